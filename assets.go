@@ -0,0 +1,57 @@
+package wallie
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// embedded holds the html templates and the vendored static assets compiled into the binary.
+//
+//go:embed tpl/*.html static
+var embedded embed.FS
+
+var assets struct {
+	sync.RWMutex
+	dir string
+}
+
+// UseAssetsDir reads the templates and static assets from dir rather than the copies
+// compiled into the binary so they can be edited without a rebuild. dir must contain the
+// tpl and static directories, an empty dir restores the compiled copies.
+func UseAssetsDir(dir string) {
+	assets.Lock()
+	defer assets.Unlock()
+	assets.dir = dir
+}
+
+// Assets returns the file system the templates and static assets are read from.
+func Assets() fs.FS {
+	assets.RLock()
+	defer assets.RUnlock()
+	if assets.dir != "" {
+		return os.DirFS(assets.dir)
+	}
+	return embedded
+}
+
+// VendorDir is where copies of CDN assets are kept, each under its CDN host and path.
+const VendorDir = "static/vendor/"
+
+// Asset returns the path of the vendored copy of the CDN asset at raw. Pages never load
+// from the CDN, make assets downloads any copy which is missing.
+func Asset(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	return "/" + VendorDir + u.Host + u.Path
+}
+
+// TemplateFuncs are the template functions for referencing static assets.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{"asset": Asset}
+}
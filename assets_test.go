@@ -0,0 +1,27 @@
+package wallie_test
+
+import (
+	"testing"
+
+	"github.com/nfisher/wallie"
+)
+
+func Test_Asset(t *testing.T) {
+	t.Parallel()
+
+	td := []struct {
+		raw  string
+		want string
+	}{
+		{"https://cdnjs.cloudflare.com/ajax/libs/d3/5.7.0/d3.min.js", "/static/vendor/cdnjs.cloudflare.com/ajax/libs/d3/5.7.0/d3.min.js"},
+		{"https://use.fontawesome.com/releases/v5.4.1/css/all.css", "/static/vendor/use.fontawesome.com/releases/v5.4.1/css/all.css"},
+		{"/static/app.js", "/static/app.js"},
+	}
+
+	for _, tc := range td {
+		got := wallie.Asset(tc.raw)
+		if got != tc.want {
+			t.Errorf("got Asset(%q) = %q, want %q", tc.raw, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Package client is a Go client for the wallie JSON API described by /api/v1/openapi.json.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/nfisher/wallie/csrf"
+	"github.com/nfisher/wallie/project"
+)
+
+// New creates a client for the wallie server at baseURL authenticated with the Jira session cookies.
+func New(baseURL string, cookies ...*http.Cookie) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Cookies: cookies,
+		HTTP:    http.DefaultClient,
+	}
+}
+
+// Client is a wallie API client.
+type Client struct {
+	BaseURL string
+	Cookies []*http.Cookie
+	HTTP    *http.Client
+
+	// the CSRF token and its cookie are captured from responses and sent with changes.
+	mu        sync.Mutex
+	token     string
+	csrf      *http.Cookie
+	hasPrimed bool
+}
+
+// Error is an unsuccessful response from the API.
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d => %s", e.Status, e.Message)
+}
+
+// ListStories lists the stories of projectID that are not done.
+func (c *Client) ListStories(projectID string) (project.Backlog, error) {
+	var backlog project.Backlog
+	err := c.do(http.MethodGet, storiesPath(projectID), nil, &backlog)
+	return backlog, err
+}
+
+// ListGroups lists the stories of projectID grouped by size.
+func (c *Client) ListGroups(projectID string) ([]*project.Group, error) {
+	var groups []*project.Group
+	err := c.do(http.MethodGet, "/api/v1/projects/"+url.PathEscape(projectID)+"/groups", nil, &groups)
+	return groups, err
+}
+
+// GetStory retrieves the story id.
+func (c *Client) GetStory(projectID, id string) (project.Story, error) {
+	var story project.Story
+	err := c.do(http.MethodGet, storiesPath(projectID)+"/"+url.PathEscape(id), nil, &story)
+	return story, err
+}
+
+// PatchStory updates the fields of story id which are set in patch.
+func (c *Client) PatchStory(projectID, id string, patch project.StoryPatch) (project.Story, error) {
+	var story project.Story
+	err := c.do(http.MethodPatch, storiesPath(projectID)+"/"+url.PathEscape(id), &patch, &story)
+	return story, err
+}
+
+// Resize changes the size of story id.
+func (c *Client) Resize(projectID, id string, size project.Size) (project.Story, error) {
+	return c.PatchStory(projectID, id, project.StoryPatch{Size: &size})
+}
+
+func storiesPath(projectID string) string {
+	return "/api/v1/projects/" + url.PathEscape(projectID) + "/stories"
+}
+
+func (c *Client) do(method, path string, body, v interface{}) error {
+	if method != http.MethodGet {
+		c.prime()
+	}
+
+	var buf bytes.Buffer
+	if body != nil {
+		err := json.NewEncoder(&buf).Encode(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, c.BaseURL+path, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for _, cookie := range c.Cookies {
+		req.AddCookie(cookie)
+	}
+
+	c.mu.Lock()
+	if c.csrf != nil {
+		req.AddCookie(c.csrf)
+	}
+	if c.token != "" {
+		req.Header.Set(csrf.HeaderName, c.token)
+	}
+	c.mu.Unlock()
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.capture(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr project.APIError
+		err = json.NewDecoder(resp.Body).Decode(&apiErr)
+		if err != nil {
+			return &Error{Status: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		}
+		return &Error{Status: apiErr.Error.Status, Message: apiErr.Error.Message}
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// prime fetches a CSRF token before the first change, a failure is left for the change
+// itself to report and priming is retried before the next change.
+func (c *Client) prime() {
+	c.mu.Lock()
+	hasPrimed := c.hasPrimed
+	c.mu.Unlock()
+	if hasPrimed {
+		return
+	}
+
+	var doc json.RawMessage
+	err := c.do(http.MethodGet, project.OpenAPIPath, nil, &doc)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.hasPrimed = true
+	c.mu.Unlock()
+}
+
+func (c *Client) capture(resp *http.Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if token := resp.Header.Get(csrf.HeaderName); token != "" {
+		c.token = token
+	}
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == csrf.CookieName {
+			c.csrf = &http.Cookie{Name: cookie.Name, Value: cookie.Value}
+		}
+	}
+}
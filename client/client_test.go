@@ -0,0 +1,118 @@
+package client_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nfisher/wallie/client"
+	"github.com/nfisher/wallie/csrf"
+	"github.com/nfisher/wallie/project"
+)
+
+func Test_Resize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPatch || req.URL.Path != "/api/v1/projects/ABC/stories/ABC-1" {
+			project.WriteError(w, http.StatusNotFound, "resource not found")
+			return
+		}
+
+		c, err := req.Cookie("JSESSIONID")
+		if err != nil || c.Value != "s3cr3t" {
+			project.WriteError(w, http.StatusUnauthorized, "login required")
+			return
+		}
+
+		var patch project.StoryPatch
+		json.NewDecoder(req.Body).Decode(&patch)
+		project.WriteJSON(w, http.StatusOK, &project.Story{ID: "ABC-1", Size: *patch.Size})
+	}))
+	defer srv.Close()
+
+	c := client.New(srv.URL, &http.Cookie{Name: "JSESSIONID", Value: "s3cr3t"})
+	story, err := c.Resize("ABC", "ABC-1", project.Large)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if story.Size != project.Large {
+		t.Errorf("got size = %v, want L", story.Size)
+	}
+
+	_, err = c.GetStory("ABC", "ABC-1")
+	apiErr, ok := err.(*client.Error)
+	if !ok || apiErr.Status != http.StatusNotFound {
+		t.Errorf("got err = %v, want 404 API error", err)
+	}
+}
+
+func Test_Resize_sends_csrf_token(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(project.OpenAPIPath, project.OpenAPIHandler)
+	mux.HandleFunc("/api/v1/projects/ABC/stories/ABC-1", func(w http.ResponseWriter, req *http.Request) {
+		var patch project.StoryPatch
+		json.NewDecoder(req.Body).Decode(&patch)
+		project.WriteJSON(w, http.StatusOK, &project.Story{ID: "ABC-1", Size: *patch.Size})
+	})
+	protect := csrf.New([]byte("secret"), "JSESSIONID", false)
+	protect.Fail = func(w http.ResponseWriter, req *http.Request) {
+		project.WriteError(w, http.StatusForbidden, "invalid CSRF token")
+	}
+	srv := httptest.NewServer(protect.Handler(mux))
+	defer srv.Close()
+
+	c := client.New(srv.URL, &http.Cookie{Name: "JSESSIONID", Value: "s3cr3t"})
+	for _, size := range []project.Size{project.Large, project.Small} {
+		story, err := c.Resize("ABC", "ABC-1", size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if story.Size != size {
+			t.Errorf("got size = %v, want %v", story.Size, size)
+		}
+	}
+}
+
+func Test_Resize_primes_after_failure(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(project.OpenAPIPath, project.OpenAPIHandler)
+	mux.HandleFunc("/api/v1/projects/ABC/stories/ABC-1", func(w http.ResponseWriter, req *http.Request) {
+		var patch project.StoryPatch
+		json.NewDecoder(req.Body).Decode(&patch)
+		project.WriteJSON(w, http.StatusOK, &project.Story{ID: "ABC-1", Size: *patch.Size})
+	})
+	protect := csrf.New([]byte("secret"), "JSESSIONID", false)
+	protect.Fail = func(w http.ResponseWriter, req *http.Request) {
+		project.WriteError(w, http.StatusForbidden, "invalid CSRF token")
+	}
+	var primes int32
+	h := protect.Handler(mux)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// the first prime fails before it reaches the CSRF protection.
+		if req.URL.Path == project.OpenAPIPath && atomic.AddInt32(&primes, 1) == 1 {
+			project.WriteError(w, http.StatusServiceUnavailable, "unavailable")
+			return
+		}
+		h.ServeHTTP(w, req)
+	}))
+	defer srv.Close()
+
+	c := client.New(srv.URL, &http.Cookie{Name: "JSESSIONID", Value: "s3cr3t"})
+	_, err := c.Resize("ABC", "ABC-1", project.Large)
+	apiErr, ok := err.(*client.Error)
+	if !ok || apiErr.Status != http.StatusForbidden {
+		t.Fatalf("got err = %v, want 403 without a CSRF token", err)
+	}
+
+	story, err := c.Resize("ABC", "ABC-1", project.Small)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if story.Size != project.Small {
+		t.Errorf("got size = %v, want S", story.Size)
+	}
+	if n := atomic.LoadInt32(&primes); n != 2 {
+		t.Errorf("got %v primes, want 2 after the first failed", n)
+	}
+}
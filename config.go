@@ -6,4 +6,145 @@ type Config struct {
 	SessionName      string
 	AlwaysReloadHTML bool `json:"-"`
 	IsInsecure       bool
+	// HistoryPath is the file estimation changes are appended to.
+	HistoryPath string
+	// IndexPath is the file the similar stories index is stored in.
+	IndexPath string
+	// ExemplarPath is the file pinned exemplar stories are stored in.
+	ExemplarPath string
+
+	// DefaultScale is the name of the estimation scale used when a project has none assigned.
+	DefaultScale string
+	// ProjectScales maps a project ID to the name of its estimation scale.
+	ProjectScales map[string]string
+	// Scales are custom estimation scales in addition to the built-in ones.
+	Scales []Scale
+
+	// Wallboard configures the kiosk display served at /wallboard.
+	Wallboard Wallboard
+
+	// ServiceUser and ServicePassword are the Jira credentials used by read-only views
+	// when the viewer has no session of their own. Share links and the wallboard are
+	// disabled without them.
+	ServiceUser     string
+	ServicePassword string
+	// ShareSecret signs the share tokens which open read-only views without a login.
+	ShareSecret string
+	// CSRFSecret signs the tokens which protect forms and the page to return to after a
+	// login, a random secret is used when empty.
+	CSRFSecret string
+	// LandingPath is the page users are sent to after a login when there is no page to
+	// return to, it defaults to /tshirt.
+	LandingPath string
+
+	// Roles maps Jira user names to a wallie role of facilitator, estimator or viewer. Roles
+	// only narrow the Jira permissions of a user, they never grant more.
+	Roles map[string]string
+	// DefaultRole is the role of users missing from Roles, it defaults to facilitator.
+	DefaultRole string
+
+	// AccessLog configures the request log.
+	AccessLog AccessLog
+	// Tracing configures where request traces are exported.
+	Tracing Tracing
+	// Server configures the timeouts and TLS of the HTTP server.
+	Server Server
+	// JiraClient configures the timeouts and connection pool of requests to Jira.
+	JiraClient JiraClient
+	// Security configures the security headers of responses.
+	Security Security
+}
+
+// AccessLog configures how the requests served are logged.
+type AccessLog struct {
+	// Format is text, json, logfmt or combined, it defaults to text.
+	Format string
+	// Fields limits the json and logfmt lines to some of user, project, bytes and latency,
+	// all of them are logged when it's empty.
+	Fields []string
+	// Skip lists paths which are not logged, it defaults to /favicon.ico, /healthz and
+	// /readyz when absent.
+	Skip []string
+}
+
+// Server configures the HTTP server, unset timeouts use the defaults in brackets.
+type Server struct {
+	// ReadTimeoutSeconds limits reading a request including its body (15).
+	ReadTimeoutSeconds int
+	// WriteTimeoutSeconds limits writing a response, it includes the Jira calls of streamed
+	// pages (60).
+	WriteTimeoutSeconds int
+	// IdleTimeoutSeconds is how long a keep-alive connection waits for a request (120).
+	IdleTimeoutSeconds int
+	// ShutdownTimeoutSeconds is how long in-flight requests have to complete when the
+	// server is stopped (30).
+	ShutdownTimeoutSeconds int
+	// CertFile and KeyFile serve HTTPS when set.
+	CertFile string
+	KeyFile  string
+	// DisableHTTP2 limits HTTPS to HTTP/1.1.
+	DisableHTTP2 bool
+}
+
+// JiraClient configures requests to Jira, unset values use the defaults in brackets.
+type JiraClient struct {
+	// TimeoutSeconds limits each request including reading the response (30).
+	TimeoutSeconds int
+	// MaxIdleConns is the number of connections kept open for reuse (16).
+	MaxIdleConns int
+	// IdleConnTimeoutSeconds is how long an unused connection is kept open (90).
+	IdleConnTimeoutSeconds int
+	// Retries is how many times a GET is repeated after a connection error or a 502, 503
+	// or 504 from Jira (2), a negative value disables retries.
+	Retries int
+}
+
+// Security configures the security headers sent with every response.
+type Security struct {
+	// FrameAncestors are the origins allowed to show the pages in a frame, for example
+	// the dashboard of a kiosk. The pages can't be framed when it's empty.
+	FrameAncestors []string
+	// ReferrerPolicy defaults to same-origin so share tokens aren't sent to other sites.
+	ReferrerPolicy string
+	// HSTSSeconds is the Strict-Transport-Security max-age of HTTPS responses (one year),
+	// a negative value omits the header.
+	HSTSSeconds int
+	// ReportOnly reports Content-Security-Policy violations in the browser console rather
+	// than blocking them.
+	ReportOnly bool
+}
+
+// Tracing configures where the spans of traced requests are sent.
+type Tracing struct {
+	// Exporter is otlp, stdout or none, tracing is disabled when it's empty.
+	Exporter string
+	// Endpoint is the OTLP/HTTP traces URL, it defaults to http://localhost:4318/v1/traces.
+	Endpoint string
+	// ServiceName names the service in exported traces, it defaults to wallie.
+	ServiceName string
+}
+
+// Wallboard lists the projects and views a wallboard cycles through.
+type Wallboard struct {
+	Projects []string
+	// Views are shown in order for each project, tshirt, flow and kanban are supported.
+	Views []string
+	// RotateSeconds is how long each view is displayed.
+	RotateSeconds int
+	// RefreshSeconds is how often the project data is reloaded from Jira.
+	RefreshSeconds int
+}
+
+// Scale is a named estimation scale as described in the configuration file.
+type Scale struct {
+	Name  string
+	Sizes []ScaleSize
+}
+
+// ScaleSize is a single size within a scale and its story point value.
+type ScaleSize struct {
+	Name   string
+	Points float64
+	// Legacy are older point values that should also be read as this size.
+	Legacy []float64
 }
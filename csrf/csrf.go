@@ -0,0 +1,173 @@
+// Package csrf protects state changing requests with tokens bound to the browser and
+// Jira sessions of the user.
+package csrf
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+)
+
+const (
+	// CookieName is the cookie holding the random browser session the token is bound to.
+	CookieName = "wallieCSRF"
+	// HeaderName is the request header a token can be sent in, responses carry the token
+	// in the same header for API clients.
+	HeaderName = "X-CSRF-Token"
+	// FieldName is the form field a token can be sent in.
+	FieldName = "csrf_token"
+)
+
+// maxFormSize limits the body read to find the token, it is larger than the biggest form
+// which is a CSV import.
+const maxFormSize = 2 << 20
+
+type tokenKey struct{}
+
+// Protect validates the token of every POST, PUT, PATCH and DELETE request.
+type Protect struct {
+	secret      []byte
+	sessionName string
+	secure      bool
+	// Fail writes the response for a request with a missing or invalid token.
+	Fail http.HandlerFunc
+}
+
+// New creates a Protect signing tokens with secret, tokens are bound to the cookie
+// sessionName so they change when the user logs in.
+func New(secret []byte, sessionName string, secure bool) *Protect {
+	return &Protect{
+		secret:      secret,
+		sessionName: sessionName,
+		secure:      secure,
+		Fail: func(w http.ResponseWriter, req *http.Request) {
+			http.Error(w, "missing or invalid CSRF token", http.StatusForbidden)
+		},
+	}
+}
+
+// Handler adds the token of the request to its context and rejects unsafe requests which
+// do not carry it.
+func (p *Protect) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c, err := req.Cookie(CookieName)
+		if err != nil || c.Value == "" {
+			c = &http.Cookie{
+				Name:     CookieName,
+				Value:    random(),
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   p.secure,
+				SameSite: http.SameSiteLaxMode,
+			}
+			http.SetCookie(w, c)
+		}
+
+		var session string
+		sc, err := req.Cookie(p.sessionName)
+		if err == nil {
+			session = sc.Value
+		}
+
+		token := p.token(c.Value, session)
+		w.Header().Set(HeaderName, mask(token))
+		req = req.WithContext(context.WithValue(req.Context(), tokenKey{}, token))
+
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		default:
+			if !valid(w, req, token) {
+				p.Fail(w, req)
+				return
+			}
+		}
+
+		h.ServeHTTP(w, req)
+	})
+}
+
+func (p *Protect) token(browser, session string) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(browser + "\n" + session))
+	return mac.Sum(nil)
+}
+
+func valid(w http.ResponseWriter, req *http.Request, token []byte) bool {
+	submitted := req.Header.Get(HeaderName)
+	if submitted == "" {
+		req.Body = http.MaxBytesReader(w, req.Body, maxFormSize)
+		submitted = req.FormValue(FieldName)
+	}
+	unmasked, ok := unmask(submitted)
+	return ok && hmac.Equal(unmasked, token)
+}
+
+// mask XORs token with a random pad and prepends the pad. The token is the same for every
+// page of a session so it is masked afresh each time it is rendered, otherwise a gzipped
+// page reflecting attacker input would reveal it through its compressed length (BREACH).
+func mask(token []byte) string {
+	b := make([]byte, 2*len(token))
+	_, err := rand.Read(b[:len(token)])
+	if err != nil {
+		panic(err)
+	}
+	for i, v := range token {
+		b[len(token)+i] = v ^ b[i]
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// unmask reverses mask, ok is false when s is not a masked token.
+func unmask(s string) (token []byte, ok bool) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) != 2*sha256.Size {
+		return nil, false
+	}
+	pad, token := b[:sha256.Size], b[sha256.Size:]
+	for i := range token {
+		token[i] ^= pad[i]
+	}
+	return token, true
+}
+
+// Token returns the token of req masked with a new pad on every call, it is empty when
+// req did not pass through a Protect.
+func Token(req *http.Request) string {
+	token, ok := req.Context().Value(tokenKey{}).([]byte)
+	if !ok {
+		return ""
+	}
+	return mask(token)
+}
+
+// Funcs returns the template functions for req, csrfField renders a hidden input holding
+// the token. A nil req renders nothing and is used when parsing the templates.
+func Funcs(req *http.Request) template.FuncMap {
+	return template.FuncMap{
+		"csrfField": func() template.HTML {
+			if req == nil {
+				return ""
+			}
+			return template.HTML(`<input type="hidden" name="` + FieldName + `" value="` + template.HTMLEscapeString(Token(req)) + `">`)
+		},
+	}
+}
+
+// Secret returns a random secret for when none is configured, tokens signed with it do
+// not survive a restart.
+func Secret() []byte {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func random() string {
+	return base64.RawURLEncoding.EncodeToString(Secret())
+}
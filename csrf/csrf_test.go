@@ -0,0 +1,121 @@
+package csrf_test
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/nfisher/wallie/csrf"
+)
+
+func protected() http.Handler {
+	form := template.Must(template.New("form").Funcs(csrf.Funcs(nil)).Parse(`<form method="post">{{ csrfField }}</form>`))
+	return csrf.New([]byte("secret"), "JSESSIONID", true).Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			template.Must(form.Clone()).Funcs(csrf.Funcs(req)).Execute(w, nil)
+		}
+	}))
+}
+
+var fieldToken = regexp.MustCompile(`name="csrf_token" value="([^"]+)"`)
+
+// get returns the tokens of the response header and hidden field and the browser cookie.
+func get(t *testing.T, h http.Handler, cookies ...*http.Cookie) (string, string, *http.Cookie) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	h.ServeHTTP(w, req)
+
+	browser := cookies
+	for _, c := range w.Result().Cookies() {
+		if c.Name == csrf.CookieName {
+			if !c.HttpOnly || !c.Secure || c.SameSite != http.SameSiteLaxMode {
+				t.Errorf("got cookie %+v, want HttpOnly, Secure and SameSite=Lax", c)
+			}
+			browser = append(browser, &http.Cookie{Name: c.Name, Value: c.Value})
+		}
+	}
+
+	token := w.Header().Get(csrf.HeaderName)
+	m := fieldToken.FindStringSubmatch(w.Body.String())
+	if m == nil {
+		t.Fatalf("got body %v, want the token in a hidden field", w.Body.String())
+	}
+	if token == "" || m[1] == token {
+		t.Errorf("got header token %q and field token %q, want a differently masked token in each", token, m[1])
+	}
+
+	for _, c := range browser {
+		if c.Name == csrf.CookieName {
+			return token, m[1], c
+		}
+	}
+	t.Fatal("got no CSRF cookie")
+	return "", "", nil
+}
+
+func Test_Protect(t *testing.T) {
+	t.Parallel()
+	h := protected()
+	session := &http.Cookie{Name: "JSESSIONID", Value: "abc"}
+	token, field, browser := get(t, h, session)
+	again, _, _ := get(t, h, session, browser)
+	if again == token {
+		t.Errorf("got token %v on both requests, want it masked afresh", token)
+	}
+
+	td := []struct {
+		name    string
+		method  string
+		cookies []*http.Cookie
+		header  string
+		form    string
+		status  int
+	}{
+		{"form token", http.MethodPost, []*http.Cookie{session, browser}, "", field, http.StatusOK},
+		{"header token in form", http.MethodPost, []*http.Cookie{session, browser}, "", token, http.StatusOK},
+		{"token of a later response", http.MethodPost, []*http.Cookie{session, browser}, "", again, http.StatusOK},
+		{"header token", http.MethodPatch, []*http.Cookie{session, browser}, token, "", http.StatusOK},
+		{"delete with header token", http.MethodDelete, []*http.Cookie{session, browser}, token, "", http.StatusOK},
+		{"missing token", http.MethodPost, []*http.Cookie{session, browser}, "", "", http.StatusForbidden},
+		{"wrong token", http.MethodPut, []*http.Cookie{session, browser}, "forged", "", http.StatusForbidden},
+		{"token of another session", http.MethodPost, []*http.Cookie{{Name: "JSESSIONID", Value: "xyz"}, browser}, "", token, http.StatusForbidden},
+		{"missing browser cookie", http.MethodPost, []*http.Cookie{session}, "", token, http.StatusForbidden},
+	}
+
+	for _, tc := range td {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			body := url.Values{"csrf_token": {tc.form}}.Encode()
+			req := httptest.NewRequest(tc.method, "/", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tc.header != "" {
+				req.Header.Set(csrf.HeaderName, tc.header)
+			}
+			for _, c := range tc.cookies {
+				req.AddCookie(c)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != tc.status {
+				t.Errorf("got status = %v, want %v", w.Code, tc.status)
+			}
+		})
+	}
+}
+
+func Test_Funcs_without_request(t *testing.T) {
+	t.Parallel()
+	field := csrf.Funcs(nil)["csrfField"].(func() template.HTML)()
+	if field != "" {
+		t.Errorf("got %q, want empty field", field)
+	}
+}
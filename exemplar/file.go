@@ -0,0 +1,114 @@
+package exemplar
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/nfisher/wallie/project"
+)
+
+// New creates an exemplar store persisted to the file at path.
+func New(path string) *File {
+	return &File{path: path}
+}
+
+// File stores the pinned exemplars of all projects in a single JSON file.
+type File struct {
+	path string
+	sync.Mutex
+}
+
+// Pin adds story to the exemplars of projectID replacing any previous pin of the same story.
+func (f *File) Pin(projectID string, story project.Story) error {
+	f.Lock()
+	defer f.Unlock()
+
+	m, err := f.read()
+	if err != nil {
+		return err
+	}
+
+	m[projectID] = append(without(m[projectID], story.ID), story)
+
+	return f.write(m)
+}
+
+// Unpin removes the story id from the exemplars of projectID.
+func (f *File) Unpin(projectID, id string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	m, err := f.read()
+	if err != nil {
+		return err
+	}
+
+	m[projectID] = without(m[projectID], id)
+
+	return f.write(m)
+}
+
+// List returns the exemplars of projectID in the order they were pinned.
+func (f *File) List(projectID string) ([]project.Story, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	m, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return m[projectID], nil
+}
+
+func (f *File) read() (map[string][]project.Story, error) {
+	m := make(map[string][]project.Story)
+
+	r, err := os.Open(f.path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	err = json.NewDecoder(r).Decode(&m)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (f *File) write(m map[string][]project.Story) error {
+	tmp := f.path + ".tmp"
+	w, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	err = json.NewEncoder(w).Encode(m)
+	if err != nil {
+		w.Close()
+		return err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, f.path)
+}
+
+func without(stories []project.Story, id string) []project.Story {
+	var ss []project.Story
+	for _, v := range stories {
+		if v.ID != id {
+			ss = append(ss, v)
+		}
+	}
+	return ss
+}
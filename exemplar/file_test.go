@@ -0,0 +1,59 @@
+package exemplar_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nfisher/wallie/exemplar"
+	"github.com/nfisher/wallie/project"
+)
+
+func Test_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exemplar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := exemplar.New(filepath.Join(dir, "exemplars.json"))
+
+	err = f.Pin("ABC", project.Story{ID: "ABC-1", Size: project.Small})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.Pin("ABC", project.Story{ID: "ABC-2", Size: project.Large})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// re-pinning replaces the story rather than duplicating it.
+	err = f.Pin("ABC", project.Story{ID: "ABC-1", Size: project.Medium})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ss, err := f.List("ABC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 2 {
+		t.Fatalf("got len(List()) = %v, want 2", len(ss))
+	}
+	if ss[1].ID != "ABC-1" || ss[1].Size != project.Medium {
+		t.Errorf("got %+v, want ABC-1 sized M", ss[1])
+	}
+
+	err = f.Unpin("ABC", "ABC-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ss, err = f.List("ABC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 1 {
+		t.Errorf("got len(List()) = %v, want 1", len(ss))
+	}
+}
@@ -0,0 +1,84 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/nfisher/wallie/project"
+)
+
+// New creates a history which appends changes as JSON lines to the file at path.
+func New(path string) *File {
+	return &File{path: path}
+}
+
+// File is an append-only estimation history stored as JSON lines.
+type File struct {
+	path string
+	sync.Mutex
+}
+
+// Record appends change to the history file.
+func (f *File) Record(change project.Change) error {
+	f.Lock()
+	defer f.Unlock()
+
+	w, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	err = json.NewEncoder(w).Encode(&change)
+	if err != nil {
+		w.Close()
+		return err
+	}
+
+	return w.Close()
+}
+
+// Project returns all of the changes for projectID in the order they were recorded.
+func (f *File) Project(projectID string) ([]project.Change, error) {
+	return f.filter(func(c *project.Change) bool {
+		return c.Project == projectID
+	})
+}
+
+// Story returns all of the changes for the story id in the order they were recorded.
+func (f *File) Story(projectID, id string) ([]project.Change, error) {
+	return f.filter(func(c *project.Change) bool {
+		return c.Project == projectID && c.ID == id
+	})
+}
+
+func (f *File) filter(fn func(*project.Change) bool) ([]project.Change, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	var changes []project.Change
+
+	r, err := os.Open(f.path)
+	if os.IsNotExist(err) {
+		return changes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var c project.Change
+		err = json.Unmarshal(scanner.Bytes(), &c)
+		if err != nil {
+			return nil, err
+		}
+		if fn(&c) {
+			changes = append(changes, c)
+		}
+	}
+
+	return changes, scanner.Err()
+}
@@ -0,0 +1,62 @@
+package history_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nfisher/wallie/history"
+	"github.com/nfisher/wallie/project"
+)
+
+func Test_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	h := history.New(filepath.Join(dir, "history.jsonl"))
+
+	changes, err := h.Project("ABC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("got len(changes) = %v, want 0", len(changes))
+	}
+
+	td := []project.Change{
+		{Time: time.Now(), User: "nfisher", Project: "ABC", ID: "ABC-1", From: project.Unsized, To: project.Small},
+		{Time: time.Now(), User: "nfisher", Project: "ABC", ID: "ABC-2", From: project.Unsized, To: project.Large},
+		{Time: time.Now(), User: "jdoe", Project: "ABC", ID: "ABC-1", From: project.Small, To: project.Medium},
+		{Time: time.Now(), User: "jdoe", Project: "DEF", ID: "DEF-1", From: project.Unsized, To: project.Medium},
+	}
+	for _, c := range td {
+		err = h.Record(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changes, err = h.Project("ABC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 3 {
+		t.Errorf("got len(Project()) = %v, want 3", len(changes))
+	}
+
+	changes, err = h.Story("ABC", "ABC-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("got len(Story()) = %v, want 2", len(changes))
+	}
+	if changes[1].To != project.Medium || changes[1].User != "jdoe" {
+		t.Errorf("got %+v, want jdoe resize to M", changes[1])
+	}
+}
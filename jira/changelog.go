@@ -0,0 +1,79 @@
+package jira
+
+import "time"
+
+// jiraTime is the timestamp format used by the Jira REST API.
+const jiraTime = "2006-01-02T15:04:05.000-0700"
+
+type Changelog struct {
+	Histories []History `json:"histories"`
+}
+
+type History struct {
+	Created string        `json:"created"`
+	Items   []HistoryItem `json:"items"`
+}
+
+type HistoryItem struct {
+	Field      string `json:"field"`
+	FromString string `json:"fromString"`
+	ToString   string `json:"toString"`
+}
+
+// CycleTime is the duration between the first status change of the issue and its
+// resolution. It returns false when the issue is unresolved or the times are invalid.
+func (i Issue) CycleTime() (time.Duration, bool) {
+	var start, end time.Time
+	var err error
+
+	if i.Fields.Created != "" {
+		start, err = time.Parse(jiraTime, i.Fields.Created)
+		if err != nil {
+			return 0, false
+		}
+	}
+
+	var transitions []time.Time
+	if i.Changelog != nil {
+		for _, h := range i.Changelog.Histories {
+			for _, item := range h.Items {
+				if item.Field != "status" {
+					continue
+				}
+				t, err := time.Parse(jiraTime, h.Created)
+				if err != nil {
+					return 0, false
+				}
+				transitions = append(transitions, t)
+			}
+		}
+	}
+
+	if len(transitions) > 0 {
+		start = transitions[0]
+		end = transitions[len(transitions)-1]
+	}
+
+	if i.Fields.ResolutionDate != "" {
+		end, err = time.Parse(jiraTime, i.Fields.ResolutionDate)
+		if err != nil {
+			return 0, false
+		}
+	}
+
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0, false
+	}
+
+	return end.Sub(start), true
+}
+
+// Resolved returns the resolution date of the issue, the zero time is returned when it is
+// unresolved or the date cannot be parsed.
+func (i Issue) Resolved() time.Time {
+	t, err := time.Parse(jiraTime, i.Fields.ResolutionDate)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
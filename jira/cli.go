@@ -0,0 +1,317 @@
+package jira
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/history"
+	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/share"
+	"github.com/nfisher/wallie/tui"
+)
+
+var stdout io.Writer = os.Stdout
+
+// list prints the stories of a project that are not done.
+func list(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	configPath := configFlag(fs)
+	projectID := fs.String("project", "dmp", "project ID to query on the command-line")
+	format := fs.String("format", "table", "output format: table or json")
+	unsized := fs.Bool("unsized", false, "only list stories which need estimating")
+	fs.Parse(args)
+
+	c, _, err := cliClient(*configPath)
+	if err != nil {
+		return err
+	}
+
+	backlog, err := c.ListStories(*projectID)
+	if err != nil {
+		return err
+	}
+
+	if *unsized {
+		backlog.Stories = backlog.BySize()[0].Stories
+	}
+
+	switch *format {
+	case "json":
+		return writeJSON(stdout, backlog.Stories)
+	case "table":
+		return writeTable(stdout, backlog.Stories)
+	}
+
+	return fmt.Errorf("unknown format %q", *format)
+}
+
+// estimate sizes a single story, for example `walliej estimate ABC-123 M`.
+func estimate(args []string) error {
+	fs := flag.NewFlagSet("estimate", flag.ExitOnError)
+	configPath := configFlag(fs)
+	projectID := fs.String("project", "", "project ID of the story, defaults to the prefix of the key")
+	format := fs.String("format", "table", "output format: table or json")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: walliej estimate [flags] KEY SIZE")
+	}
+	key, size := fs.Arg(0), project.Size(fs.Arg(1))
+
+	if !validKey.MatchString(key) {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	if *projectID == "" {
+		*projectID = projectOf(key)
+	}
+
+	c, config, err := cliClient(*configPath)
+	if err != nil {
+		return err
+	}
+
+	story, err := project.Resize(c, history.New(config.HistoryPath), project.ScaleFor(config, *projectID), *projectID, key, project.StoryPatch{Size: &size}, "cli", "")
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		return writeJSON(stdout, story)
+	}
+	return writeTable(stdout, []project.Story{story})
+}
+
+// export writes the backlog of a project to stdout as CSV, JSON or Markdown.
+func export(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	configPath := configFlag(fs)
+	projectID := fs.String("project", "dmp", "project ID to query on the command-line")
+	format := fs.String("format", "json", "output format: csv, json or markdown")
+	grouped := fs.Bool("grouped", false, "group the stories by size")
+	fs.Parse(args)
+
+	if _, ok := project.ExportFormats[*format]; !ok {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	c, _, err := cliClient(*configPath)
+	if err != nil {
+		return err
+	}
+
+	backlog, err := c.ListStories(*projectID)
+	if err != nil {
+		return err
+	}
+
+	return project.Export(stdout, backlog, *format, *grouped)
+}
+
+// importCSV creates the stories in a CSV file, for example `walliej import -project ABC stories.csv`.
+func importCSV(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	configPath := configFlag(fs)
+	projectID := fs.String("project", "dmp", "project ID to create the stories in")
+	dryRun := fs.Bool("dry-run", false, "validate the stories without creating them")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: walliej import [flags] FILE")
+	}
+
+	r, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	c, config, err := cliClient(*configPath)
+	if err != nil {
+		return err
+	}
+
+	rows, err := project.ParseImport(r, project.ScaleFor(config, *projectID))
+	if err != nil {
+		return err
+	}
+	rows = project.Import(c, *projectID, rows, *dryRun)
+
+	var failed int
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "LINE\tSTATUS\tKEY\tTITLE")
+	for _, row := range rows {
+		status := "ok"
+		switch {
+		case !row.OK():
+			status = row.Error
+			failed++
+		case row.Created:
+			status = "created"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", row.Line, status, row.Story.ID, row.Story.Title)
+	}
+	tw.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", failed, len(rows))
+	}
+	return nil
+}
+
+// interactive walks the unsized stories of a project in the terminal, see tui.Session.
+func interactive(args []string) error {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	configPath := configFlag(fs)
+	projectID := fs.String("project", "dmp", "project ID to query on the command-line")
+	fs.Parse(args)
+
+	c, config, err := cliClient(*configPath)
+	if err != nil {
+		return err
+	}
+
+	restore, err := cbreak()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "unable to read single keypresses, press enter after each key:", err)
+	} else {
+		defer restore()
+	}
+
+	session := tui.Session{
+		Client:  c,
+		History: history.New(config.HistoryPath),
+		Scale:   project.ScaleFor(config, *projectID),
+		Project: *projectID,
+	}
+
+	return session.Run(os.Stdin, stdout)
+}
+
+// cbreak switches the terminal to read single keypresses without echo and returns a
+// function which restores the previous terminal settings.
+func cbreak() (func(), error) {
+	stty := func(args ...string) ([]byte, error) {
+		cmd := exec.Command("stty", args...)
+		cmd.Stdin = os.Stdin
+		return cmd.Output()
+	}
+
+	state, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = stty("cbreak", "-echo")
+	if err != nil {
+		return nil, err
+	}
+
+	return func() { stty(strings.TrimSpace(string(state))) }, nil
+}
+
+// cliClient creates a client from the configuration authenticated with either the session
+// in JIRA_SESSION or the credentials in JIRA_USER and JIRA_PASSWORD.
+func cliClient(configPath string) (project.Client, wallie.Config, error) {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return nil, config, err
+	}
+
+	client, err := envClient(config)
+	return client, config, err
+}
+
+// envClient authenticates using the JIRA_SESSION or JIRA_USER and JIRA_PASSWORD
+// environment variables.
+var envClient = func(config wallie.Config) (project.Client, error) {
+	cookies, err := envCookies(config)
+	if err != nil {
+		return nil, err
+	}
+	return New(config, cookies), nil
+}
+
+// envCookies returns the JIRA_SESSION cookie or logs in as JIRA_USER with JIRA_PASSWORD.
+func envCookies(config wallie.Config) ([]*http.Cookie, error) {
+	session := os.Getenv("JIRA_SESSION")
+	if session != "" {
+		return []*http.Cookie{{Name: config.SessionName, Value: session}}, nil
+	}
+
+	username := os.Getenv("JIRA_USER")
+	if username == "" {
+		return nil, fmt.Errorf("JIRA_SESSION or JIRA_USER and JIRA_PASSWORD must be set")
+	}
+
+	return Authenticate(config, username, os.Getenv("JIRA_PASSWORD"))
+}
+
+func projectOf(key string) string {
+	for i := len(key) - 1; i >= 0; i-- {
+		if key[i] == '-' {
+			return key[:i]
+		}
+	}
+	return key
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeTable(w io.Writer, stories []project.Story) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tSIZE\tTITLE")
+	for _, s := range stories {
+		size := s.Size
+		if size == project.Unsized {
+			size = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.ID, size, s.Title)
+	}
+	return tw.Flush()
+}
+
+func shareLink(args []string) error {
+	fs := flag.NewFlagSet("share", flag.ExitOnError)
+	configPath := configFlag(fs)
+	projectID := fs.String("project", "", "project ID the link is for")
+	ttl := fs.Duration("ttl", 30*24*time.Hour, "how long the link is valid for")
+	base := fs.String("base", "", "base URL of the wallie server, e.g. https://wallie.example.com")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: share [flags] PATH")
+	}
+	path := fs.Arg(0)
+	if !readOnlyPaths[path] {
+		return fmt.Errorf("%v cannot be shared, expected one of /flow, /kanban or /wallboard", path)
+	}
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if config.ServiceUser == "" {
+		return fmt.Errorf("share links need a Jira service account, set ServiceUser or JIRA_SERVICE_USER")
+	}
+
+	link, err := share.URL(config.ShareSecret, path, *projectID, time.Now().Add(*ttl))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(stdout, *base+link)
+	return nil
+}
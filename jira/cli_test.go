@@ -0,0 +1,164 @@
+package jira_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie/history"
+	"github.com/nfisher/wallie/jira"
+	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/project/projecttest"
+)
+
+// cliFixture returns a client with a sized and an unsized story, the path of a
+// configuration keeping its history in a temporary directory and the history path.
+func cliFixture(t *testing.T) (*projecttest.Client, string, string) {
+	dir := t.TempDir()
+	historyPath := filepath.Join(dir, "history.jsonl")
+	config, err := json.Marshal(map[string]string{"JiraBase": "https://jira.example.com", "HistoryPath": historyPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config.json")
+	err = ioutil.WriteFile(configPath, config, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &projecttest.Client{
+		Stories: []project.Story{
+			{ID: "ABC-1", Title: "Add login page", Size: project.Small},
+			{ID: "ABC-2", Title: "Create service skeleton", Size: project.Unsized},
+		},
+	}
+	return client, configPath, historyPath
+}
+
+func Test_list(t *testing.T) {
+	client, configPath, _ := cliFixture(t)
+
+	var out bytes.Buffer
+	err := jira.Run(client, &out, "list", "-config", configPath, "-project", "ABC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"KEY", "ABC-1  S     Add login page", "ABC-2  -     Create service skeleton"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("got %q, want it to contain %q", out.String(), want)
+		}
+	}
+
+	out.Reset()
+	err = jira.Run(client, &out, "list", "-config", configPath, "-project", "ABC", "-unsized", "-format", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stories []project.Story
+	err = json.Unmarshal(out.Bytes(), &stories)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stories) != 1 || stories[0].ID != "ABC-2" {
+		t.Errorf("got %+v, want only ABC-2", stories)
+	}
+
+	err = jira.Run(client, &out, "list", "-config", configPath, "-format", "xml")
+	if err == nil {
+		t.Error("got nil error, want unknown format error")
+	}
+}
+
+func Test_estimate(t *testing.T) {
+	client, configPath, historyPath := cliFixture(t)
+
+	var out bytes.Buffer
+	err := jira.Run(client, &out, "estimate", "-config", configPath, "ABC-2", "L")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Stories[1].Size != project.Large {
+		t.Errorf("got ABC-2 size = %v, want %v", client.Stories[1].Size, project.Large)
+	}
+	if !strings.Contains(out.String(), "ABC-2  L     Create service skeleton") {
+		t.Errorf("got %q, want the resized story", out.String())
+	}
+
+	changes, err := history.New(historyPath).Project("ABC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].To != project.Large || changes[0].Session != "cli" {
+		t.Errorf("got history %+v, want single change to L from the cli", changes)
+	}
+
+	td := []struct {
+		name string
+		args []string
+	}{
+		{"missing size", []string{"ABC-2"}},
+		{"invalid key", []string{"ABC", "L"}},
+		{"unknown size", []string{"ABC-2", "XXXL"}},
+	}
+
+	for _, tc := range td {
+		err := jira.Run(client, &out, append([]string{"estimate", "-config", configPath}, tc.args...)...)
+		if err == nil {
+			t.Errorf("%v: got nil error, want error", tc.name)
+		}
+	}
+}
+
+func Test_export(t *testing.T) {
+	client, configPath, _ := cliFixture(t)
+
+	var out bytes.Buffer
+	err := jira.Run(client, &out, "export", "-config", configPath, "-project", "ABC", "-format", "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "Key,Title,Size") || !strings.HasPrefix(lines[1], "ABC-1,Add login page,S") {
+		t.Errorf("got %q, want a header and two stories", out.String())
+	}
+
+	err = jira.Run(client, &out, "export", "-config", configPath, "-format", "xml")
+	if err == nil {
+		t.Error("got nil error, want unknown format error")
+	}
+}
+
+func Test_import(t *testing.T) {
+	client, configPath, _ := cliFixture(t)
+	csvPath := filepath.Join(filepath.Dir(configPath), "stories.csv")
+	err := ioutil.WriteFile(csvPath, []byte("Size,Title\nM,Export backlog\nXXXL,Too big\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err = jira.Run(client, &out, "import", "-config", configPath, "-project", "ABC", "-dry-run", csvPath)
+	if err == nil || err.Error() != "1 of 2 rows failed" {
+		t.Errorf("got err %v, want 1 of 2 rows failed", err)
+	}
+	if len(client.Stories) != 2 {
+		t.Errorf("got len(stories) = %v after dry-run, want 2", len(client.Stories))
+	}
+
+	out.Reset()
+	err = jira.Run(client, &out, "import", "-config", configPath, "-project", "ABC", csvPath)
+	if err == nil {
+		t.Error("got nil error, want failed rows error")
+	}
+	if len(client.Stories) != 3 || client.Stories[2].Title != "Export backlog" {
+		t.Errorf("got %+v, want Export backlog created", client.Stories)
+	}
+	for _, want := range []string{"2     created", "3     unknown size"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("got %q, want it to contain %q", out.String(), want)
+		}
+	}
+}
@@ -2,17 +2,35 @@ package jira
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"math"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/nfisher/wallie"
 	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/trace"
 )
 
+// ErrUnauthorized is returned when Jira rejects the session of a request.
+var ErrUnauthorized = errors.New("jira session is not authorised")
+
+// unexpectedStatus returns an error for an unexpected response status, it wraps
+// ErrUnauthorized for a 401.
+func unexpectedStatus(code int) error {
+	if code == http.StatusUnauthorized {
+		return fmt.Errorf("unexpected status code %v: %w", code, ErrUnauthorized)
+	}
+	return fmt.Errorf("unexpected status code %v", code)
+}
+
 func New(config wallie.Config, cookies []*http.Cookie) project.Client {
 	return &CookieClient{
 		Config:  config,
@@ -24,116 +42,160 @@ func New(config wallie.Config, cookies []*http.Cookie) project.Client {
 type CookieClient struct {
 	Config  wallie.Config
 	Cookies []*http.Cookie
+
+	ctx context.Context
+}
+
+// WithContext returns a copy of the client whose requests are traced as part of ctx.
+func (c *CookieClient) WithContext(ctx context.Context) project.Client {
+	cc := *c
+	cc.ctx = ctx
+	return &cc
+}
+
+func (c *CookieClient) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
 }
 
 // ListStories outputs a list of stories that are not done.
 func (c *CookieClient) ListStories(projectID string) (project.Backlog, error) {
+	scale := project.ScaleFor(c.Config, projectID)
 	backlog := project.Backlog{
 		Project: projectID,
 		Stories: []project.Story{},
 		BaseURL: c.Config.JiraBase + "/browse/",
+		Scale:   scale,
 	}
 
-	ss, err := ListIssues(c.Config, projectID, c.Cookies)
+	ss, err := ListIssues(c.context(), c.Config, projectID, c.Cookies)
 	if err != nil {
 		return backlog, err
 	}
 
 	for _, s := range ss {
-		story := project.Story{
-			Description: s.Fields.Description,
-			Title:       s.Fields.Summary,
-			ID:          s.Key,
-			Author:      s.Fields.Reporter.DisplayName,
-			Size:        points2size(s.Fields.StoryPoints),
-		}
-		backlog.Stories = append(backlog.Stories, story)
+		backlog.Stories = append(backlog.Stories, issue2story(s, scale))
 	}
 
 	return backlog, nil
 }
 
-func (c *CookieClient) CreateStory(projectID, title, description, size string) error {
-	sz := size2points(size)
-	if size == "" {
-		sz = math.NaN()
+// GetStory retrieves a single story.
+func (c *CookieClient) GetStory(projectID, id string) (project.Story, error) {
+	issue, err := GetIssue(c.Config, id, c.Cookies)
+	if err != nil {
+		return project.Story{}, err
 	}
-	log.Printf("create new story in %v project, size = %v\n", projectID, sz)
 
-	return nil
+	return issue2story(*issue, project.ScaleFor(c.Config, projectID)), nil
 }
 
-type CreateIssueRequest struct {
-	/*
-		{
-			"fields": {
-			   "project":
-			   {
-				  "key": "<PROJECT_KEY>"
-			   },
-			   "summary": "REST EXAMPLE",
-			   "description": "Creating an issue via REST API",
-			   "issuetype": {
-				  "name": "Bug"
-			   }
-			  }
-			}
-	*/
+// ListCompleted lists the stories completed since the provided time with their cycle time.
+func (c *CookieClient) ListCompleted(projectID string, since time.Time) ([]project.Completed, error) {
+	scale := project.ScaleFor(c.Config, projectID)
+
+	ss, err := ListCompletedIssues(c.context(), c.Config, projectID, since, c.Cookies)
+	if err != nil {
+		return nil, err
+	}
+
+	var completed []project.Completed
+	for _, s := range ss {
+		d, ok := s.CycleTime()
+		if !ok {
+			continue
+		}
+		completed = append(completed, project.Completed{
+			Story:     issue2story(s, scale),
+			CycleTime: d,
+			Resolved:  s.Resolved(),
+		})
+	}
+
+	return completed, nil
 }
 
-func (c *CookieClient) UpdateStory(projectID, id, title, description, size string) error {
-	sz := size2points(size)
+// User returns the name of the user the session belongs to.
+func (c *CookieClient) User() (string, error) {
+	user, err := CurrentUser(c.Config, c.Cookies)
+	if err == nil {
+		sessions.remember(sessionID(c.Config, c.Cookies), user, time.Now())
+	}
+	return user, err
+}
+
+// CanEdit returns true when the user has the EDIT_ISSUES permission in the project.
+func (c *CookieClient) CanEdit(projectID string) (bool, error) {
+	return HasPermission(c.Config, projectID, "EDIT_ISSUES", c.Cookies)
+}
+
+func issue2story(s Issue, scale project.Scale) project.Story {
+	story := project.Story{
+		Description: s.Fields.Description,
+		Title:       s.Fields.Summary,
+		ID:          s.Key,
+		Size:        scale.Size(s.Fields.StoryPoints),
+	}
+	if s.Fields.Reporter != nil {
+		story.Author = s.Fields.Reporter.DisplayName
+	}
+	if s.Fields.Status != nil {
+		story.Status = s.Fields.Status.Name
+	}
+	return story
+}
+
+// CreateStory creates a new story in the project and returns it with its assigned key.
+func (c *CookieClient) CreateStory(projectID, title, description, size string) (project.Story, error) {
+	sz := c.points(projectID, size)
+	log.Printf("create new story in %v project, size = %v\n", projectID, sz)
+
+	key, err := CreateIssue(c.Config, projectID, title, description, sz, c.Cookies)
+	if err != nil {
+		return project.Story{}, err
+	}
+
+	story := project.Story{
+		ID:          key,
+		Title:       title,
+		Description: description,
+		Size:        project.Size(size),
+	}
 	if size == "" {
-		sz = math.NaN()
+		story.Size = project.Unsized
 	}
+
+	return story, nil
+}
+
+// UpdateStory updates a story, an Unsized size clears the story points of the story.
+func (c *CookieClient) UpdateStory(projectID, id, title, description, size string) error {
 	log.Printf("update story %v:%v - %v\n", projectID, id, size)
-	return UpdateIssue(c.Config, id, title, description, sz, c.Cookies)
-}
-
-func size2points(size string) float64 {
-	switch project.Size(size) {
-	case project.ExtraSmall:
-		return 1.0
-	case project.Small:
-		return 2.0
-	case project.Medium:
-		return 3.0
-	case project.Large:
-		return 5.0
-	case project.ExtraLarge:
-		return 8.0
-	case project.ExtraExtraLarge:
-		return 13.0
-	}
-
-	return 0.0
-}
-
-func points2size(p float64) project.Size {
-	switch p {
-	case 1.0:
-		return project.ExtraSmall
-	case 2.0:
-		return project.Small
-	case 3.0:
-		return project.Medium
-	case 5.0:
-		return project.Large
-	case 8.0:
-		return project.ExtraLarge
-	case 10.0:
-		return project.ExtraLarge
-	case 13.0:
-		return project.ExtraExtraLarge
-	case 20.0:
-		return project.ExtraExtraLarge
-	}
-	return project.Unsized
-}
-
-var client = http.Client{}
-
-func UpdateIssue(config wallie.Config, key, summary, description string, estimate float64, cookies []*http.Cookie) error {
+	if project.Size(size) == project.Unsized {
+		return ClearEstimate(c.context(), c.Config, id, title, description, c.Cookies)
+	}
+	sz := c.points(projectID, size)
+	return UpdateIssue(c.context(), c.Config, id, title, description, sz, c.Cookies)
+}
+
+// points converts size to story points using the projects scale, NaN is returned when
+// the size is not part of the scale.
+func (c *CookieClient) points(projectID, size string) float64 {
+	sz, ok := project.ScaleFor(c.Config, projectID).Points(project.Size(size))
+	if !ok {
+		return math.NaN()
+	}
+	return sz
+}
+
+var client = http.Client{
+	Transport: newTransport(wallie.JiraClient{}),
+	Timeout:   defaultJiraTimeout,
+}
+
+func UpdateIssue(ctx context.Context, config wallie.Config, key, summary, description string, estimate float64, cookies []*http.Cookie) error {
 	updateRequest := UpdateIssueRequest{
 		Fields: IssueFields{
 			Summary:     summary,
@@ -145,7 +207,32 @@ func UpdateIssue(config wallie.Config, key, summary, description string, estimat
 		updateRequest.Fields.StoryPoints = estimate
 	}
 
-	b, err := json.Marshal(updateRequest)
+	return putIssue(ctx, config, key, &updateRequest, cookies)
+}
+
+// ClearEstimate updates the summary and description of an issue and removes its story points.
+func ClearEstimate(ctx context.Context, config wallie.Config, key, summary, description string, cookies []*http.Cookie) error {
+	clearRequest := ClearEstimateRequest{
+		Fields: ClearEstimateFields{
+			Summary:     summary,
+			Description: description,
+		},
+	}
+
+	return putIssue(ctx, config, key, &clearRequest, cookies)
+}
+
+func putIssue(ctx context.Context, config wallie.Config, key string, v interface{}, cookies []*http.Cookie) error {
+	ctx, span := trace.Start(ctx, "jira.UpdateIssue", trace.Client)
+	span.Set("issue", key)
+	err := updateIssue(ctx, config, key, v, cookies)
+	span.Fail(err)
+	span.Finish()
+	return err
+}
+
+func updateIssue(ctx context.Context, config wallie.Config, key string, v interface{}, cookies []*http.Cookie) error {
+	b, err := json.Marshal(v)
 	if err != nil {
 		return err
 	}
@@ -154,6 +241,7 @@ func UpdateIssue(config wallie.Config, key, summary, description string, estimat
 	if err != nil {
 		return err
 	}
+	req = req.WithContext(ctx)
 	req.Header.Add("Content-Type", "application/json")
 	for _, c := range cookies {
 		req.AddCookie(c)
@@ -181,39 +269,275 @@ type UpdateIssueRequest struct {
 	Fields IssueFields `json:"fields"`
 }
 
-func ListIssues(config wallie.Config, projectID string, cookies []*http.Cookie) (Issues, error) {
+type ClearEstimateRequest struct {
+	Fields ClearEstimateFields `json:"fields"`
+}
+
+// ClearEstimateFields always encodes the story points so a null value removes them.
+type ClearEstimateFields struct {
+	Summary     string   `json:"summary"`
+	Description string   `json:"description"`
+	StoryPoints *float64 `json:"customfield_10006"`
+}
+
+func CreateIssue(config wallie.Config, projectID, summary, description string, estimate float64, cookies []*http.Cookie) (string, error) {
+	createRequest := CreateIssueRequest{
+		Fields: CreateIssueFields{
+			Project:     ProjectKey{Key: projectID},
+			Summary:     summary,
+			Description: description,
+			IssueType:   IssueType{Name: "Story"},
+		},
+	}
+
+	if !math.IsNaN(estimate) {
+		createRequest.Fields.StoryPoints = &estimate
+	}
+
+	b, err := json.Marshal(&createRequest)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/rest/api/2/issue", config.JiraBase), bytes.NewBuffer(b))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		b, err = ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+
+		return "", fmt.Errorf("%d => %s", resp.StatusCode, b)
+	}
+
+	var created CreateIssueResp
+	err = json.NewDecoder(resp.Body).Decode(&created)
+	if err != nil {
+		return "", err
+	}
+
+	return created.Key, nil
+}
+
+type CreateIssueRequest struct {
+	Fields CreateIssueFields `json:"fields"`
+}
+
+type CreateIssueFields struct {
+	Project     ProjectKey `json:"project"`
+	Summary     string     `json:"summary"`
+	Description string     `json:"description"`
+	IssueType   IssueType  `json:"issuetype"`
+	StoryPoints *float64   `json:"customfield_10006,omitempty"`
+}
+
+type ProjectKey struct {
+	Key string `json:"key"`
+}
+
+type IssueType struct {
+	Name string `json:"name"`
+}
+
+type CreateIssueResp struct {
+	ID   string `json:"id"`
+	Key  string `json:"key"`
+	Self string `json:"self"`
+}
+
+func GetIssue(config wallie.Config, key string, cookies []*http.Cookie) (*Issue, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/rest/api/2/issue/%s?fields=%s", config.JiraBase, key, strings.Join(issueFields, ",")), nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, unexpectedStatus(resp.StatusCode)
+	}
+
+	var issue Issue
+	err = json.NewDecoder(resp.Body).Decode(&issue)
+	if err != nil {
+		return nil, err
+	}
+
+	return &issue, nil
+}
+
+func CurrentUser(config wallie.Config, cookies []*http.Cookie) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/rest/auth/1/session", config.JiraBase), nil)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", unexpectedStatus(resp.StatusCode)
+	}
+
+	var session SessionResp
+	err = json.NewDecoder(resp.Body).Decode(&session)
+	if err != nil {
+		return "", err
+	}
+
+	return session.Name, nil
+}
+
+// HasPermission returns true when the session holds permission in the project.
+func HasPermission(config wallie.Config, projectID, permission string, cookies []*http.Cookie) (bool, error) {
+	q := url.Values{}
+	q.Set("projectKey", projectID)
+	q.Set("permissions", permission)
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/rest/api/2/mypermissions?%s", config.JiraBase, q.Encode()), nil)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, unexpectedStatus(resp.StatusCode)
+	}
+
+	var permissions PermissionsResp
+	err = json.NewDecoder(resp.Body).Decode(&permissions)
+	if err != nil {
+		return false, err
+	}
+
+	return permissions.Permissions[permission].HavePermission, nil
+}
+
+// Authenticate creates a Jira session for the user and returns the session cookies.
+func Authenticate(config wallie.Config, username, password string) ([]*http.Cookie, error) {
+	b, err := json.Marshal(&LoginRequest{Username: username, Password: password})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/rest/auth/1/session", config.JiraBase), bytes.NewBuffer(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("authentication failed with status code %v", resp.StatusCode)
+	}
+
+	return resp.Cookies(), nil
+}
+
+type SessionResp struct {
+	Name string `json:"name"`
+}
+
+// ListIssues lists the stories of a project which are not done.
+func ListIssues(ctx context.Context, config wallie.Config, projectID string, cookies []*http.Cookie) (Issues, error) {
+	if !validProjectID.MatchString(projectID) {
+		return nil, fmt.Errorf("%w %q", project.ErrInvalidProject, projectID)
+	}
+	searchRequest := SearchRequest{
+		JQL:    fmt.Sprintf(`type = Story AND project = "%s" AND status not in (Done, Closed) ORDER BY rank`, projectID),
+		Fields: issueFields,
+	}
+	return searchAll(ctx, config, searchRequest, cookies)
+}
+
+// ListCompletedIssues lists the stories resolved since the provided time including their changelog.
+func ListCompletedIssues(ctx context.Context, config wallie.Config, projectID string, since time.Time, cookies []*http.Cookie) (Issues, error) {
+	if !validProjectID.MatchString(projectID) {
+		return nil, fmt.Errorf("%w %q", project.ErrInvalidProject, projectID)
+	}
+	searchRequest := SearchRequest{
+		JQL:    fmt.Sprintf(`type = Story AND project = "%s" AND status in (Done, Closed) AND resolutiondate >= "%s" ORDER BY resolutiondate`, projectID, since.Format("2006-01-02")),
+		Fields: append([]string{"created", "resolutiondate"}, issueFields...),
+		Expand: []string{"changelog"},
+	}
+	return searchAll(ctx, config, searchRequest, cookies)
+}
+
+func searchAll(ctx context.Context, config wallie.Config, searchRequest SearchRequest, cookies []*http.Cookie) (Issues, error) {
 	var isLast = false
 	var issues Issues
 	var page = 0
-	client := &http.Client{}
 
 	for !isLast {
-		queryResp, err := paginatedSearch(config, projectID, cookies, client, page)
+		queryResp, err := paginatedSearch(ctx, config, searchRequest, cookies, &client, page)
 		if err != nil {
-			log.Println(err)
+			return issues, err
 		}
+		jiraPages.Inc()
 
 		issues = append(issues, queryResp.Issues...)
-		isLast = queryResp.Total == len(issues)
+		isLast = queryResp.Total <= len(issues) || len(queryResp.Issues) == 0
 		log.Printf("read %v issues, starting at %v, max %v, is last %v\n", len(queryResp.Issues), queryResp.StartAt, queryResp.Total, isLast)
 		page++
 	}
 	return issues, nil
 }
 
-func paginatedSearch(config wallie.Config, projectID string, cookies []*http.Cookie, client *http.Client, page int) (*QueryResp, error) {
-	const pageSize = 100
-	searchRequest := SearchRequest{
-		JQL:        fmt.Sprintf(`type = Story AND project = "%s" AND status not in (Done, Closed) ORDER BY rank`, projectID),
-		StartAt:    pageSize * page,
-		MaxResults: pageSize,
-		Fields: []string{
-			"summary",
-			"customfield_10006",
-			"description",
-			"reporter",
-		},
+// paginatedSearch fetches a page of the search results in its own span.
+func paginatedSearch(ctx context.Context, config wallie.Config, searchRequest SearchRequest, cookies []*http.Cookie, client *http.Client, page int) (*QueryResp, error) {
+	ctx, span := trace.Start(ctx, "jira.search", trace.Client)
+	span.Set("page", page)
+	queryResp, err := searchPage(ctx, config, searchRequest, cookies, client, page)
+	span.Fail(err)
+	if err == nil {
+		span.Set("issues", len(queryResp.Issues))
 	}
+	span.Finish()
+	return queryResp, err
+}
+
+func searchPage(ctx context.Context, config wallie.Config, searchRequest SearchRequest, cookies []*http.Cookie, client *http.Client, page int) (*QueryResp, error) {
+	const pageSize = 100
+	searchRequest.StartAt = pageSize * page
+	searchRequest.MaxResults = pageSize
 
 	b, err := json.Marshal(searchRequest)
 	if err != nil {
@@ -224,6 +548,7 @@ func paginatedSearch(config wallie.Config, projectID string, cookies []*http.Coo
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 	req.Header.Add("Content-Type", "application/json")
 
 	for _, c := range cookies {
@@ -237,7 +562,7 @@ func paginatedSearch(config wallie.Config, projectID string, cookies []*http.Coo
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code %v", resp.StatusCode)
+		return nil, unexpectedStatus(resp.StatusCode)
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
@@ -255,6 +580,14 @@ func paginatedSearch(config wallie.Config, projectID string, cookies []*http.Coo
 	return &queryResp, nil
 }
 
+var issueFields = []string{
+	"summary",
+	"customfield_10006",
+	"description",
+	"reporter",
+	"status",
+}
+
 type QueryResp struct {
 	MaxResults int    `json:"maxResults"`
 	Total      int    `json:"total"`
@@ -265,78 +598,22 @@ type QueryResp struct {
 
 type Issues []Issue
 
-func (i Issues) rank(r float64) Issues {
-	var issues Issues
-	for _, v := range i {
-		if r == 0.0 && v.Fields.StoryPoints < 1.0 {
-			issues = append(issues, v)
-		} else if v.Fields.StoryPoints == r {
-			issues = append(issues, v)
-		}
-	}
-	return issues
-}
-
-// XS, S, M, L, XL, XXL
-// 1,  2, 3, 5, 10, 20
-
-const (
-	unknown         = -1.0
-	undefined       = 0.0
-	extraSmall      = 1.0
-	small           = 2.0
-	medium          = 3.0
-	large           = 5.0
-	extraLarge      = 8.0
-	extraExtraLarge = 13.0
-)
-
-func (i Issues) ExtraSmall() Issues {
-	return i.rank(extraSmall)
-}
-
-func (i Issues) Small() Issues {
-	return i.rank(small)
-}
-
-func (i Issues) Medium() Issues {
-	return i.rank(medium)
-}
-
-func (i Issues) Large() Issues {
-	return i.rank(large)
-}
-
-func (i Issues) ExtraLarge() Issues {
-	// rank(10) is to support legacy XL
-	return append(i.rank(extraLarge), i.rank(10)...)
-}
-
-func (i Issues) ExtraExtraLarge() Issues {
-	// rank(20) is to support legacy XXL
-	return append(i.rank(extraExtraLarge), i.rank(20)...)
-}
-
-func (i Issues) Unknown() Issues {
-	return i.rank(-1.0)
-}
-
-func (i Issues) Other() Issues {
-	return i.rank(0.0)
-}
-
 type Issue struct {
-	Key    string      `json:"key"`
-	Self   string      `json:"self"`
-	Fields IssueFields `json:"fields"`
+	Key       string      `json:"key"`
+	Self      string      `json:"self"`
+	Fields    IssueFields `json:"fields"`
+	Changelog *Changelog  `json:"changelog,omitempty"`
 }
 
 type IssueFields struct {
-	Project     Project   `json:"project,omitempty"`
-	Summary     string    `json:"summary"`
-	Description string    `json:"description"`
-	StoryPoints float64   `json:"customfield_10006,omitempty"`
-	Reporter    *Reporter `json:"reporter,omitempty"`
+	Project        Project   `json:"project,omitempty"`
+	Summary        string    `json:"summary"`
+	Description    string    `json:"description"`
+	StoryPoints    float64   `json:"customfield_10006,omitempty"`
+	Reporter       *Reporter `json:"reporter,omitempty"`
+	Status         *Status   `json:"status,omitempty"`
+	Created        string    `json:"created,omitempty"`
+	ResolutionDate string    `json:"resolutiondate,omitempty"`
 }
 
 type Project struct {
@@ -347,9 +624,23 @@ type Reporter struct {
 	DisplayName string `json:"displayName"`
 }
 
+type Status struct {
+	Name string `json:"name"`
+}
+
+// PermissionsResp is the response of the mypermissions resource.
+type PermissionsResp struct {
+	Permissions map[string]Permission `json:"permissions"`
+}
+
+type Permission struct {
+	HavePermission bool `json:"havePermission"`
+}
+
 type SearchRequest struct {
 	JQL        string   `json:"jql"`
 	StartAt    int      `json:"startAt"`
 	MaxResults int      `json:"maxResults"`
 	Fields     []string `json:"fields"`
+	Expand     []string `json:"expand,omitempty"`
 }
@@ -0,0 +1,45 @@
+package jira_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/jira"
+	"github.com/nfisher/wallie/project"
+)
+
+func Test_ListIssues_invalid_project(t *testing.T) {
+	t.Parallel()
+	var searches int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&searches, 1)
+		w.Write([]byte(`{"total":0,"issues":[]}`))
+	}))
+	defer srv.Close()
+
+	config := wallie.Config{JiraBase: srv.URL}
+	injected := `ABC" OR project = "XYZ`
+
+	_, err := jira.ListIssues(context.Background(), config, injected, nil)
+	if !errors.Is(err, project.ErrInvalidProject) {
+		t.Errorf("ListIssues got err %v, want %v", err, project.ErrInvalidProject)
+	}
+	_, err = jira.ListCompletedIssues(context.Background(), config, injected, time.Now(), nil)
+	if !errors.Is(err, project.ErrInvalidProject) {
+		t.Errorf("ListCompletedIssues got err %v, want %v", err, project.ErrInvalidProject)
+	}
+	if n := atomic.LoadInt32(&searches); n != 0 {
+		t.Errorf("got %v searches, want none", n)
+	}
+
+	_, err = jira.ListIssues(context.Background(), config, "ABC", nil)
+	if err != nil {
+		t.Errorf("got err %v for a valid project, want nil", err)
+	}
+}
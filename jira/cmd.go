@@ -3,23 +3,64 @@ package jira
 import (
 	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/csrf"
+	"github.com/nfisher/wallie/exemplar"
+	"github.com/nfisher/wallie/history"
+	"github.com/nfisher/wallie/metrics"
 	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/redirect"
 	"github.com/nfisher/wallie/reqlog"
+	"github.com/nfisher/wallie/secure"
+	"github.com/nfisher/wallie/similar"
+	"github.com/nfisher/wallie/trace"
 )
 
+// Execute runs the subcommand named by the first argument, the server is started when
+// no subcommand is provided.
 func Execute(version, origin string) error {
-	var configPath string
+	return run(version, origin, os.Args[1:])
+}
+
+func run(version, origin string, args []string) error {
+	name := "serve"
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		name, args = args[0], args[1:]
+	}
+
+	switch name {
+	case "serve":
+		return serve(version, origin, args)
+	case "list":
+		return list(args)
+	case "estimate":
+		return estimate(args)
+	case "export":
+		return export(args)
+	case "import":
+		return importCSV(args)
+	case "tui":
+		return interactive(args)
+	case "share":
+		return shareLink(args)
+	}
+
+	return fmt.Errorf("unknown command %q, expected one of serve, list, estimate, export, import, tui or share", name)
+}
+
+func serve(version, origin string, args []string) error {
 	var addr string
-	var projectID string
 	var alwaysReload bool
+	var assetsDir string
 	var isInsecure bool
 	var port = DefaultAddress()
-	var jiraBase = os.Getenv("JIRA_BASE")
 
 	log.SetOutput(os.Stdout)
 	log.SetFlags(log.LstdFlags | log.LUTC | log.Lshortfile)
@@ -27,46 +68,241 @@ func Execute(version, origin string) error {
 	log.Println("version:", version)
 	log.Println("source:", origin)
 
-	flag.BoolVar(&isInsecure, "insecure", false, "local development insecure cookies")
-	flag.BoolVar(&alwaysReload, "reload", false, "always reload HTML templates")
-	flag.StringVar(&configPath, "config", "config.json", "path to the configuration file")
-	flag.StringVar(&addr, "listen", port, "listening address")
-	flag.StringVar(&projectID, "project", "dmp", "project ID to query on the command-line")
-	flag.Parse()
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	configPath := configFlag(fs)
+	fs.BoolVar(&isInsecure, "insecure", false, "local development insecure cookies")
+	fs.BoolVar(&alwaysReload, "reload", false, "always reload HTML templates from the assets directory")
+	fs.StringVar(&assetsDir, "assets", "", "directory containing tpl and static to use instead of the compiled copies, defaults to . with -reload")
+	fs.StringVar(&addr, "listen", port, "listening address")
+	fs.Parse(args)
 
-	config, err := readConfig(configPath)
-	if err != nil && jiraBase == "" {
+	config, err := loadConfig(*configPath)
+	if err != nil {
 		return err
 	}
-	if config.SessionName == "" {
-		config.SessionName = "JSESSIONID"
-	}
-	if config.LoginPath == "" {
-		config.LoginPath = "/login"
-	}
-	if jiraBase != "" {
-		config.JiraBase = jiraBase
-	}
 	if isInsecure {
 		config.IsInsecure = true
 		log.Println("overriding secure cookies")
 	}
 
 	config.AlwaysReloadHTML = alwaysReload
+	if assetsDir == "" && alwaysReload {
+		assetsDir = "."
+	}
+	if assetsDir != "" {
+		wallie.UseAssetsDir(assetsDir)
+		tpl = parseTemplates()
+		log.Printf("reading templates and static assets from %s", assetsDir)
+	}
+	if config.CSRFSecret == "" {
+		log.Println("no CSRF secret configured, open forms will be rejected after a restart")
+		config.CSRFSecret = string(csrf.Secret())
+	}
+
+	startTracing(config.Tracing)
 
+	estimations := history.New(config.HistoryPath)
+	similarity := similar.New(config.IndexPath)
+	exemplars := exemplar.New(config.ExemplarPath)
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/favicon.ico", Favicon)
+	mux.Handle(project.StaticPrefix, project.StaticHandler())
 
-	mux.HandleFunc("/tshirt", project.TshirtHandler(New, config))
+	mux.HandleFunc("/tshirt", project.TshirtHandler(New, config, estimations, exemplars))
+	mux.HandleFunc("/history", project.HistoryHandler(estimations, config))
+	mux.HandleFunc("/accuracy", project.AccuracyHandler(New, config))
+	mux.HandleFunc(project.APIPrefix, project.APIHandler(New, config, estimations))
+	mux.HandleFunc(project.OpenAPIPath, project.OpenAPIHandler)
+	mux.HandleFunc("/export", project.ExportHandler(New, config))
+	mux.HandleFunc("/import", project.ImportHandler(New, config))
+	mux.HandleFunc("/print", project.PrintHandler(New, config))
+	mux.HandleFunc("/exemplars", project.ExemplarHandler(New, config, exemplars))
+	mux.HandleFunc("/similar", project.SimilarHandler(similarity, config))
+	mux.HandleFunc("/similar/index", project.IndexHandler(New, config, similarity))
 	mux.HandleFunc("/flow", project.FlowHandler())
 
-	mux.HandleFunc("/estimation", project.TshirtHandler(New, config))
+	service := NewServiceAccount(config)
+	mux.HandleFunc("/kanban", project.KanbanHandler(service.ReadOnly, config))
+	wallboard := project.NewWallboard(service.Client, config)
+	stop := make(chan struct{})
+	if config.ServiceUser == "" {
+		log.Println("no Jira service account configured, share links and the wallboard are disabled")
+	} else if wallboard.Slides() > 0 {
+		go wallboard.Run(stop)
+	}
+	mux.HandleFunc("/wallboard", project.WallboardHandler(wallboard, config))
+
+	mux.HandleFunc("/estimation", project.TshirtHandler(New, config, estimations, exemplars))
 	mux.HandleFunc("/sizing", SizingHandler(config))
 	mux.HandleFunc(config.LoginPath, Login(config))
+	mux.Handle(metrics.Path, metrics.Handler())
+	mux.HandleFunc(HealthPath, Health)
+	mux.HandleFunc(ReadyPath, Ready(config))
+	mux.HandleFunc(VersionPath, Version(version, origin))
+
+	logger, err := reqlog.New(reqlog.Format(config.AccessLog.Format), config.AccessLog.Fields, config.AccessLog.Skip)
+	if err != nil {
+		return err
+	}
+	logger.User = SessionUser(config)
+	logger.Project = project.RequestProject
+
+	protect := csrf.New([]byte(config.CSRFSecret), config.SessionName, !config.IsInsecure)
+	protect.Fail = CSRFFailure
+	headers := &secure.Headers{
+		FrameAncestors: config.Security.FrameAncestors,
+		ReferrerPolicy: config.Security.ReferrerPolicy,
+		HSTSSeconds:    config.Security.HSTSSeconds,
+		ReportOnly:     config.Security.ReportOnly,
+	}
 
 	log.Printf("binding to %s", addr)
-	return http.ListenAndServe(addr, reqlog.LogRequests(RequireLogin(mux, config)))
+	route := func(req *http.Request) string {
+		_, pattern := mux.Handler(req)
+		return pattern
+	}
+	h := reqlog.Measure(reqlog.Compress(headers.Handler(protect.Handler(RequireLogin(mux, config)))), route)
+	srv := newServer(addr, logger.Handler(h), config.Server)
+	shutdown := func() {
+		close(stop)
+		if t := trace.Installed(); t != nil {
+			t.Flush()
+		}
+	}
+	return listenAndServe(srv, config.Server, shutdown)
+}
+
+func configFlag(fs *flag.FlagSet) *string {
+	return fs.String("config", "config.json", "path to the configuration file")
+}
+
+// loadConfig reads the configuration file and applies the defaults and environment overrides.
+func loadConfig(configPath string) (wallie.Config, error) {
+	var jiraBase = os.Getenv("JIRA_BASE")
+
+	config, err := readConfig(configPath)
+	if err != nil && jiraBase == "" {
+		return config, err
+	}
+	if config.SessionName == "" {
+		config.SessionName = "JSESSIONID"
+	}
+	if config.LoginPath == "" {
+		config.LoginPath = "/login"
+	}
+	if config.HistoryPath == "" {
+		config.HistoryPath = "history.jsonl"
+	}
+	if config.IndexPath == "" {
+		config.IndexPath = "index.json"
+	}
+	if config.ExemplarPath == "" {
+		config.ExemplarPath = "exemplars.json"
+	}
+	if config.AccessLog.Skip == nil {
+		config.AccessLog.Skip = []string{"/favicon.ico", HealthPath, ReadyPath}
+	}
+	if config.LandingPath == "" {
+		config.LandingPath = "/tshirt"
+	}
+	if jiraBase != "" {
+		config.JiraBase = jiraBase
+	}
+	if v := os.Getenv("JIRA_SERVICE_USER"); v != "" {
+		config.ServiceUser = v
+		config.ServicePassword = os.Getenv("JIRA_SERVICE_PASSWORD")
+	}
+	if v := os.Getenv("WALLIE_SHARE_SECRET"); v != "" {
+		config.ShareSecret = v
+	}
+	if v := os.Getenv("WALLIE_CSRF_SECRET"); v != "" {
+		config.CSRFSecret = v
+	}
+	tracingEnv(&config.Tracing)
+
+	switch config.Tracing.Exporter {
+	case "", "none", "stdout", "otlp":
+	default:
+		return config, fmt.Errorf("unknown trace exporter %q, expected otlp, stdout or none", config.Tracing.Exporter)
+	}
+
+	if (config.Server.CertFile == "") != (config.Server.KeyFile == "") {
+		return config, fmt.Errorf("server certFile and keyFile must be set together")
+	}
+
+	// every command shares the Jira client.
+	configureClient(config.JiraClient)
+
+	if !redirect.Safe(config.LandingPath) {
+		return config, fmt.Errorf("landing path %q must be a path on this server", config.LandingPath)
+	}
+
+	for user, role := range config.Roles {
+		if !validRole(role) {
+			return config, fmt.Errorf("unknown role %q for %v, expected facilitator, estimator or viewer", role, user)
+		}
+	}
+	if config.DefaultRole != "" && !validRole(config.DefaultRole) {
+		return config, fmt.Errorf("unknown default role %q, expected facilitator, estimator or viewer", config.DefaultRole)
+	}
+
+	return config, nil
+}
+
+// tracingEnv applies the standard OpenTelemetry environment variables to the tracing config.
+func tracingEnv(tracing *wallie.Tracing) {
+	switch v := os.Getenv("OTEL_TRACES_EXPORTER"); v {
+	case "":
+	case "console":
+		tracing.Exporter = "stdout"
+	default:
+		tracing.Exporter = v
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); v != "" {
+		tracing.Endpoint = strings.TrimSuffix(v, "/") + "/v1/traces"
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); v != "" {
+		tracing.Endpoint = v
+	}
+	if v := os.Getenv("OTEL_SERVICE_NAME"); v != "" {
+		tracing.ServiceName = v
+	}
+	if tracing.Endpoint == "" {
+		tracing.Endpoint = "http://localhost:4318/v1/traces"
+	}
+	if tracing.ServiceName == "" {
+		tracing.ServiceName = "wallie"
+	}
+}
+
+// startTracing installs a tracer exporting to the configured destination.
+func startTracing(tracing wallie.Tracing) {
+	var exporter trace.Exporter
+	switch tracing.Exporter {
+	case "stdout":
+		exporter = trace.Stdout(os.Stdout)
+	case "otlp":
+		exporter = trace.OTLP(tracing.Endpoint, tracing.ServiceName, &http.Client{Timeout: 10 * time.Second})
+	default:
+		return
+	}
+
+	t := trace.NewTracer(exporter, 5*time.Second)
+	t.OnError = func(err error) {
+		log.Printf("unable to export traces: %v\n", err)
+	}
+	trace.Install(t)
+	log.Printf("exporting traces to %s", tracing.Exporter)
+}
+
+func validRole(role string) bool {
+	for _, r := range project.Roles {
+		if project.Role(strings.ToLower(role)) == r {
+			return true
+		}
+	}
+	return false
 }
 
 func readConfig(path string) (wallie.Config, error) {
@@ -76,6 +312,7 @@ func readConfig(path string) (wallie.Config, error) {
 	if err != nil {
 		return config, err
 	}
+	defer r.Close()
 
 	err = json.NewDecoder(r).Decode(&config)
 	if err != nil {
@@ -0,0 +1,20 @@
+package jira
+
+import (
+	"io"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/project"
+)
+
+// Run runs the subcommand in args against client in place of Jira and writes its output to
+// out. It replaces package state so tests calling it can't run in parallel.
+func Run(client project.Client, out io.Writer, args ...string) error {
+	defer func(c func(wallie.Config) (project.Client, error), w io.Writer) {
+		envClient, stdout = c, w
+	}(envClient, stdout)
+
+	envClient = func(wallie.Config) (project.Client, error) { return client, nil }
+	stdout = out
+	return run("test", "", args)
+}
@@ -2,37 +2,42 @@ package jira
 
 import (
 	"bytes"
-	"encoding/json"
-	"fmt"
 	"html/template"
 	"io"
+	"log"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/csrf"
+	"github.com/nfisher/wallie/metrics"
+	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/redirect"
+	"github.com/nfisher/wallie/secure"
 )
 
-func CumulativeFlow(w http.ResponseWriter, req *http.Request) {
-	var project = req.URL.Query().Get("project")
-	if !validProjectID.MatchString(project) {
-		http.Error(w, "unknown project ID", http.StatusNotFound)
-		return
-	}
-
-	fmt.Fprintln(w, project)
-}
-
 func Favicon(w http.ResponseWriter, req *http.Request) { io.Copy(w, bytes.NewReader(favIcon)) }
 
 func RequireLogin(h http.Handler, config wallie.Config) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		p := req.URL.EscapedPath()
-		if config.LoginPath == p || "/favicon.ico" == p {
+		if config.LoginPath == p || isPublic(p) || strings.HasPrefix(p, project.StaticPrefix) {
 			h.ServeHTTP(w, req)
 			return
 		}
 
 		_, err := req.Cookie(config.SessionName)
+		if err != nil && isShared(req, config) {
+			h.ServeHTTP(w, req)
+			return
+		}
+		if err != nil && (strings.HasPrefix(p, project.APIPrefix) || project.AcceptsJSON(req)) {
+			project.WriteError(w, http.StatusUnauthorized, "login required")
+			return
+		}
 		if err != nil {
 			// override method so that it forces form rendering
 			req.Method = http.MethodGet
@@ -44,6 +49,25 @@ func RequireLogin(h http.Handler, config wallie.Config) http.Handler {
 	})
 }
 
+// isPublic returns true for the paths which are served without a login.
+func isPublic(p string) bool {
+	switch p {
+	case "/favicon.ico", project.OpenAPIPath, metrics.Path, HealthPath, ReadyPath, VersionPath:
+		return true
+	}
+	return false
+}
+
+// CSRFFailure rejects a request without a valid CSRF token in the format the client expects.
+func CSRFFailure(w http.ResponseWriter, req *http.Request) {
+	const msg = "missing or invalid CSRF token, reload the page and try again"
+	if strings.HasPrefix(req.URL.EscapedPath(), project.APIPrefix) || project.AcceptsJSON(req) {
+		project.WriteError(w, http.StatusForbidden, msg)
+		return
+	}
+	http.Error(w, msg, http.StatusForbidden)
+}
+
 func Login(config wallie.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		if req.Method == http.MethodPost && req.URL.EscapedPath() == config.LoginPath {
@@ -53,63 +77,40 @@ func Login(config wallie.Config) http.HandlerFunc {
 				return
 			}
 
-			username := req.FormValue("email")
-			password := req.FormValue("password")
-
-			loginRequest := LoginRequest{
-				Username: username,
-				Password: password,
-			}
-
-			b, err := json.Marshal(&loginRequest)
+			cookies, err := Authenticate(config, req.FormValue("email"), req.FormValue("password"))
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
 				return
 			}
 
-			authReq, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/rest/auth/1/session", config.JiraBase), bytes.NewBuffer(b))
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
-				return
-			}
-			authReq.Header.Add("Content-Type", "application/json")
-
-			resp, err := client.Do(authReq)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
-				return
-			}
-			defer resp.Body.Close()
-
-			for _, c := range resp.Cookies() {
+			for _, c := range cookies {
 				c.MaxAge = 60 * 60 // 1 hour
 				if config.IsInsecure {
 					c.Secure = false
 				}
 				http.SetCookie(w, c)
 			}
-
-			redirectCookie, err := req.Cookie("wallieRedirect")
+			// the Jira name is remembered as User reports it rather than the email used to log in.
+			user, err := CurrentUser(config, cookies)
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusNoContent)
+				log.Printf("unable to look up the user of a new session: %v\n", err)
 			}
+			sessions.remember(sessionID(config, cookies), user, time.Now())
 
-			http.SetCookie(w, &http.Cookie{Name: "wallieRedirect", MaxAge: -1})
-			tpl.ExecuteTemplate(w, "login_redirect", redirectCookie.Value)
+			http.SetCookie(w, redirect.Clear())
+			http.Redirect(w, req, loginTarget(req, config), http.StatusSeeOther)
 			return
 		}
 
-		_, cookieErr := req.Cookie("wallieRedirect")
-		if cookieErr == http.ErrNoCookie {
-			redirectCookie := &http.Cookie{
-				Name:     "wallieRedirect",
-				Value:    fmt.Sprintf("%s?%s", req.URL.EscapedPath(), req.URL.Query().Encode()),
-				HttpOnly: true,
+		// remember the page the user was sent to the login from.
+		if req.URL.EscapedPath() != config.LoginPath {
+			c := redirect.Cookie([]byte(config.CSRFSecret), req.URL.RequestURI(), !config.IsInsecure)
+			if c != nil {
+				http.SetCookie(w, c)
 			}
-			http.SetCookie(w, redirectCookie)
 		}
 
-		err := tpl.ExecuteTemplate(w, "login", nil)
+		err := project.ExecuteTemplate(req.Context(), templates(req, config), w, "login", nil)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -117,6 +118,17 @@ func Login(config wallie.Config) http.HandlerFunc {
 	}
 }
 
+// loginTarget returns the page to send the user to after they log in, the landing page is
+// used when there is no valid target or the target is the login itself.
+func loginTarget(req *http.Request, config wallie.Config) string {
+	target := redirect.Target([]byte(config.CSRFSecret), req, config.LandingPath)
+	u, err := url.Parse(target)
+	if err != nil || u.Path == config.LoginPath {
+		return config.LandingPath
+	}
+	return target
+}
+
 // LoginRequest encapsulates a user login.
 type LoginRequest struct {
 	Username string `json:"username"`
@@ -125,37 +137,6 @@ type LoginRequest struct {
 
 func SizingHandler(config wallie.Config) func(w http.ResponseWriter, req *http.Request) {
 	return func(w http.ResponseWriter, req *http.Request) {
-		var tpl = tpl
-
-		projectID := req.URL.Query().Get("project")
-
-		if !validProjectID.MatchString(projectID) {
-			http.Error(w, "Invalid project ID", http.StatusNotFound)
-			return
-		}
-
-		if config.AlwaysReloadHTML {
-			tpl = template.Must(template.ParseGlob("tpl/*.html"))
-		}
-
-		issues, err := ListIssues(config, projectID, req.Cookies())
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		err = tpl.ExecuteTemplate(w, "sizing_board", EstimationPage{JiraBase: config.JiraBase, Issues: issues})
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-	}
-}
-
-func EstimationHandler(config wallie.Config) func(w http.ResponseWriter, req *http.Request) {
-	return func(w http.ResponseWriter, req *http.Request) {
-		var tpl = tpl
-
 		projectID := req.URL.Query().Get("project")
 
 		if !validProjectID.MatchString(projectID) {
@@ -163,40 +144,15 @@ func EstimationHandler(config wallie.Config) func(w http.ResponseWriter, req *ht
 			return
 		}
 
-		if config.AlwaysReloadHTML {
-			tpl = template.Must(template.ParseGlob("tpl/*.html"))
-		}
+		tpl := templates(req, config)
 
-		if req.Method == http.MethodPost {
-			err := req.ParseForm()
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-
-			key := req.FormValue("key")
-			if !validKey.MatchString(key) {
-				http.Error(w, "invalid key!", http.StatusBadRequest)
-				return
-			}
-			summary := req.FormValue("summary")
-			description := req.FormValue("description")
-			estimate := tee2estimate(req.FormValue("size"))
-
-			err = UpdateIssue(config, key, summary, description, estimate, req.Cookies())
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-		}
-
-		issues, err := ListIssues(config, projectID, req.Cookies())
+		issues, err := ListIssues(req.Context(), config, projectID, req.Cookies())
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		err = tpl.ExecuteTemplate(w, "estimation_board", EstimationPage{JiraBase: config.JiraBase, Issues: issues})
+		err = project.ExecuteTemplate(req.Context(), tpl, w, "sizing_board", EstimationPage{JiraBase: config.JiraBase, Issues: issues, Scale: project.ScaleFor(config, projectID)})
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -225,29 +181,41 @@ var validProjectID = regexp.MustCompile(`^\w+$`)
 type EstimationPage struct {
 	JiraBase string
 	Issues   Issues
+	Scale    project.Scale
+}
+
+// Unsized returns the issues whose story points are not part of the scale of the page.
+func (p EstimationPage) Unsized() Issues {
+	var issues Issues
+	for _, v := range p.Issues {
+		if p.Scale.Size(v.Fields.StoryPoints) == project.Unsized {
+			issues = append(issues, v)
+		}
+	}
+	return issues
 }
 
-var tpl = template.Must(template.ParseGlob("tpl/*.html"))
+var tpl = parseTemplates()
 
-var validKey = regexp.MustCompile(`^[A-Z]+-[0-9]+$`)
+func parseTemplates() *template.Template {
+	return template.Must(parseTemplateFiles())
+}
 
-func tee2estimate(size string) float64 {
-	switch size {
-	case "XS":
-		return extraSmall
-	case "S":
-		return small
-	case "M":
-		return medium
-	case "L":
-		return large
-	case "XL":
-		return extraLarge
-	case "XXL":
-		return extraExtraLarge
-	case "U":
-		return unknown
-	}
+func parseTemplateFiles() (*template.Template, error) {
+	return template.New("").
+		Funcs(csrf.Funcs(nil)).
+		Funcs(secure.Funcs(nil)).
+		Funcs(wallie.TemplateFuncs()).
+		ParseFS(wallie.Assets(), "tpl/*.html")
+}
 
-	return undefined
+// templates returns a clone of the templates with the template functions bound to req.
+func templates(req *http.Request, config wallie.Config) *template.Template {
+	t := tpl
+	if config.AlwaysReloadHTML {
+		t = parseTemplates()
+	}
+	return template.Must(t.Clone()).Funcs(csrf.Funcs(req)).Funcs(secure.Funcs(req))
 }
+
+var validKey = regexp.MustCompile(`^[A-Z]+-[0-9]+$`)
@@ -0,0 +1,106 @@
+package jira
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/project"
+)
+
+// Paths of the operational endpoints, they're served without a login.
+const (
+	HealthPath  = "/healthz"
+	ReadyPath   = "/readyz"
+	VersionPath = "/version"
+)
+
+// readyTimeout bounds how long a readiness check waits for Jira.
+const readyTimeout = 3 * time.Second
+
+// Health reports that the process is alive.
+func Health(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ok\n"))
+}
+
+// Readiness is the body of a readiness response.
+type Readiness struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Ready reports whether the templates can be parsed and Jira can be reached, it responds
+// with 503 when either fails. The reasons are logged rather than returned.
+func Ready(config wallie.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		checks := map[string]string{
+			"templates": "ok",
+			"jira":      "ok",
+		}
+		status := http.StatusOK
+
+		// the details are logged as the endpoint is public and they name internal hosts.
+		err := checkTemplates()
+		if err != nil {
+			log.Printf("readiness: templates: %v\n", err)
+			checks["templates"] = "invalid"
+			status = http.StatusServiceUnavailable
+		}
+
+		err = ServerInfo(config)
+		if err != nil {
+			log.Printf("readiness: jira: %v\n", err)
+			checks["jira"] = "unreachable"
+			status = http.StatusServiceUnavailable
+		}
+
+		r := Readiness{Status: "ok", Checks: checks}
+		if status != http.StatusOK {
+			r.Status = "unavailable"
+		}
+		project.WriteJSON(w, status, &r)
+	}
+}
+
+func checkTemplates() error {
+	_, err := parseTemplateFiles()
+	if err != nil {
+		return err
+	}
+	return project.CheckTemplates()
+}
+
+// ServerInfo returns an error when the Jira server info can't be retrieved.
+func ServerInfo(config wallie.Config) error {
+	c := http.Client{Transport: client.Transport, Timeout: readyTimeout}
+	resp, err := c.Get(fmt.Sprintf("%s/rest/api/2/serverInfo", config.JiraBase))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %v", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// BuildInfo describes the running binary.
+type BuildInfo struct {
+	Version string `json:"version"`
+	Origin  string `json:"origin"`
+	Go      string `json:"go"`
+}
+
+// Version responds with the git SHA and origin the binary was built from.
+func Version(version, origin string) http.HandlerFunc {
+	info := BuildInfo{Version: version, Origin: origin, Go: runtime.Version()}
+	return func(w http.ResponseWriter, req *http.Request) {
+		project.WriteJSON(w, http.StatusOK, &info)
+	}
+}
@@ -0,0 +1,119 @@
+package jira_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/jira"
+)
+
+func Test_Health(t *testing.T) {
+	t.Parallel()
+	w := httptest.NewRecorder()
+	jira.Health(w, httptest.NewRequest(http.MethodGet, jira.HealthPath, nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "ok\n" {
+		t.Errorf("got %v %q, want 200 ok", w.Code, w.Body)
+	}
+}
+
+func Test_Ready(t *testing.T) {
+	t.Parallel()
+	jiraUp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/rest/api/2/serverInfo" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write([]byte(`{"version":"7.12.0"}`))
+	}))
+	t.Cleanup(jiraUp.Close)
+	jiraDown := httptest.NewServer(http.NotFoundHandler())
+	jiraDown.Close()
+
+	td := []struct {
+		name   string
+		base   string
+		status int
+		want   jira.Readiness
+	}{
+		{"ready", jiraUp.URL, http.StatusOK, jira.Readiness{Status: "ok", Checks: map[string]string{"templates": "ok", "jira": "ok"}}},
+		{"jira down", jiraDown.URL, http.StatusServiceUnavailable, jira.Readiness{Status: "unavailable", Checks: map[string]string{"templates": "ok", "jira": "unreachable"}}},
+	}
+
+	for _, tc := range td {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			w := httptest.NewRecorder()
+			jira.Ready(wallie.Config{JiraBase: tc.base})(w, httptest.NewRequest(http.MethodGet, jira.ReadyPath, nil))
+
+			if w.Code != tc.status {
+				t.Errorf("got status = %v, want %v", w.Code, tc.status)
+			}
+			if strings.Contains(w.Body.String(), "127.0.0.1") {
+				t.Errorf("got body %s, want the Jira host hidden", w.Body)
+			}
+
+			var got jira.Readiness
+			err := json.NewDecoder(w.Body).Decode(&got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tc.want.Status || len(got.Checks) != len(tc.want.Checks) {
+				t.Fatalf("got %+v, want %+v", got, tc.want)
+			}
+			for k, v := range tc.want.Checks {
+				if got.Checks[k] != v {
+					t.Errorf("got check %v = %q, want %q", k, got.Checks[k], v)
+				}
+			}
+		})
+	}
+}
+
+func Test_Version(t *testing.T) {
+	t.Parallel()
+	w := httptest.NewRecorder()
+	jira.Version("abc123", "git@github.com:nfisher/wallie.git")(w, httptest.NewRequest(http.MethodGet, jira.VersionPath, nil))
+
+	var got jira.BuildInfo
+	err := json.NewDecoder(w.Body).Decode(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := jira.BuildInfo{Version: "abc123", Origin: "git@github.com:nfisher/wallie.git", Go: runtime.Version()}
+	if w.Code != http.StatusOK || got != want {
+		t.Errorf("got %v %+v, want 200 %+v", w.Code, got, want)
+	}
+}
+
+func Test_RequireLogin_public(t *testing.T) {
+	t.Parallel()
+	config := wallie.Config{SessionName: "JSESSIONID", LoginPath: "/login"}
+	h := jira.RequireLogin(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}), config)
+
+	td := []struct {
+		path   string
+		status int
+	}{
+		{jira.HealthPath, http.StatusTeapot},
+		{jira.ReadyPath, http.StatusTeapot},
+		{jira.VersionPath, http.StatusTeapot},
+		{"/api/v1/projects/ABC/stories", http.StatusUnauthorized},
+	}
+
+	for _, tc := range td {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if w.Code != tc.status {
+			t.Errorf("got %v status = %v, want %v", tc.path, w.Code, tc.status)
+		}
+	}
+}
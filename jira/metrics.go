@@ -0,0 +1,73 @@
+package jira
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nfisher/wallie/metrics"
+	"github.com/nfisher/wallie/trace"
+)
+
+var (
+	jiraRequests = metrics.Default.NewCounter("wallie_jira_requests_total",
+		"Requests made to Jira by endpoint, method and status.", "endpoint", "method", "status")
+	jiraLatency = metrics.Default.NewHistogram("wallie_jira_request_duration_seconds",
+		"Time taken by Jira to respond by endpoint and method.", metrics.DefBuckets, "endpoint", "method")
+	jiraPages = metrics.Default.NewCounter("wallie_jira_search_pages_total",
+		"Pages of search results fetched from Jira.")
+	jiraRetries = metrics.Default.NewCounter("wallie_jira_retries_total",
+		"Requests to Jira repeated after a failure.")
+)
+
+func init() {
+	// the zero value is exported so dashboards can rely on the series existing before the
+	// first retry.
+	jiraRetries.Add(0)
+}
+
+// measured records the count and latency of requests made to Jira and passes the trace
+// context on to it.
+type measured struct {
+	http.RoundTripper
+}
+
+func (t measured) RoundTrip(req *http.Request) (*http.Response, error) {
+	if trace.FromContext(req.Context()) != nil {
+		// a RoundTripper must not modify the request so the header is copied.
+		r := *req
+		r.Header = make(http.Header, len(req.Header)+1)
+		for k, v := range req.Header {
+			r.Header[k] = v
+		}
+		trace.Inject(req.Context(), r.Header)
+		req = &r
+	}
+
+	start := time.Now()
+	resp, err := t.RoundTripper.RoundTrip(req)
+
+	e := endpoint(req.URL.Path)
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	jiraRequests.Inc(e, req.Method, status)
+	jiraLatency.Observe(time.Since(start).Seconds(), e, req.Method)
+
+	return resp, err
+}
+
+// endpoint returns the REST resource of p with the issue key removed.
+func endpoint(p string) string {
+	i := strings.Index(p, "/rest/")
+	if i < 0 {
+		return "other"
+	}
+	p = p[i:]
+	if strings.HasPrefix(p, "/rest/api/2/issue/") {
+		return "/rest/api/2/issue/{key}"
+	}
+	return p
+}
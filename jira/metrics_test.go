@@ -0,0 +1,23 @@
+package jira_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_ "github.com/nfisher/wallie/jira"
+	"github.com/nfisher/wallie/metrics"
+)
+
+func Test_metrics_jira(t *testing.T) {
+	t.Parallel()
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, metrics.Path, nil))
+
+	for _, want := range []string{"# TYPE wallie_jira_requests_total counter", "# TYPE wallie_jira_retries_total counter"} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("got metrics without %q", want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package jira
+
+import (
+	"net/http"
+	"time"
+)
+
+// retryBackoff is the wait before the first retry, it doubles for each retry after that.
+const retryBackoff = 100 * time.Millisecond
+
+// retrying repeats GET and HEAD requests to Jira which fail with a connection error or a
+// 502, 503 or 504 up to retries times. Other methods may have changed Jira before failing
+// so they are never repeated.
+type retrying struct {
+	http.RoundTripper
+	retries int
+}
+
+func (t retrying) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return resp, err
+	}
+
+	wait := retryBackoff
+	for i := 0; i < t.retries && retryable(resp, err); i++ {
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		wait *= 2
+
+		jiraRetries.Inc()
+		resp, err = t.RoundTripper.RoundTrip(req)
+	}
+	return resp, err
+}
+
+// retryable returns true when the request failed before reaching Jira or Jira was
+// temporarily unable to handle it.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
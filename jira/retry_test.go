@@ -0,0 +1,76 @@
+package jira_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/jira"
+	"github.com/nfisher/wallie/metrics"
+)
+
+var retriesTotal = regexp.MustCompile(`\nwallie_jira_retries_total (\d+)\n`)
+
+func retries(t *testing.T) int {
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, metrics.Path, nil))
+	m := retriesTotal.FindStringSubmatch(w.Body.String())
+	if m == nil {
+		t.Fatal("got metrics without wallie_jira_retries_total")
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// flaky fails the first request to it with 503 Service Unavailable.
+func flaky(requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"key":"ABC-1","fields":{"summary":"Add login page"}}`))
+	}))
+}
+
+func Test_retry_get(t *testing.T) {
+	t.Parallel()
+	var requests int32
+	srv := flaky(&requests)
+	defer srv.Close()
+
+	before := retries(t)
+	issue, err := jira.GetIssue(wallie.Config{JiraBase: srv.URL}, "ABC-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.Key != "ABC-1" {
+		t.Errorf("got key %v, want ABC-1", issue.Key)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("got %v requests, want 2", n)
+	}
+	if after := retries(t); after <= before {
+		t.Errorf("got %v retries, want more than %v", after, before)
+	}
+}
+
+func Test_retry_not_idempotent(t *testing.T) {
+	t.Parallel()
+	var requests int32
+	srv := flaky(&requests)
+	defer srv.Close()
+
+	err := jira.UpdateIssue(context.Background(), wallie.Config{JiraBase: srv.URL}, "ABC-1", "Add login page", "", 3, nil)
+	if err == nil {
+		t.Error("got nil error, want the 503 reported")
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("got %v requests, want 1 as updates are not repeated", n)
+	}
+}
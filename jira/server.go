@@ -0,0 +1,124 @@
+package jira
+
+import (
+	"context"
+	"crypto/tls"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nfisher/wallie"
+)
+
+// Defaults for the server and Jira client when the configuration leaves them unset.
+const (
+	defaultReadTimeout     = 15 * time.Second
+	defaultWriteTimeout    = 60 * time.Second
+	defaultIdleTimeout     = 120 * time.Second
+	defaultShutdownTimeout = 30 * time.Second
+
+	defaultJiraTimeout     = 30 * time.Second
+	defaultJiraRetries     = 2
+	defaultMaxIdleConns    = 16
+	defaultIdleConnTimeout = 90 * time.Second
+)
+
+func seconds(n int, fallback time.Duration) time.Duration {
+	if n <= 0 {
+		return fallback
+	}
+	return time.Duration(n) * time.Second
+}
+
+// newTransport returns a pooled transport for requests to Jira.
+func newTransport(config wallie.JiraClient) http.RoundTripper {
+	maxIdle := config.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = defaultMaxIdleConns
+	}
+	retries := config.Retries
+	if retries == 0 {
+		retries = defaultJiraRetries
+	}
+	return retrying{retries: retries, RoundTripper: measured{&http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdle,
+		MaxIdleConnsPerHost:   maxIdle,
+		IdleConnTimeout:       seconds(config.IdleConnTimeoutSeconds, defaultIdleConnTimeout),
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}}}
+}
+
+// configureClient applies the Jira client settings to the client used for every request.
+func configureClient(config wallie.JiraClient) {
+	client = http.Client{
+		Transport: newTransport(config),
+		Timeout:   seconds(config.TimeoutSeconds, defaultJiraTimeout),
+	}
+}
+
+// newServer returns a server with the configured timeouts, HTTP/2 is offered over TLS
+// unless it has been disabled.
+func newServer(addr string, h http.Handler, config wallie.Server) *http.Server {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           h,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       seconds(config.ReadTimeoutSeconds, defaultReadTimeout),
+		WriteTimeout:      seconds(config.WriteTimeoutSeconds, defaultWriteTimeout),
+		IdleTimeout:       seconds(config.IdleTimeoutSeconds, defaultIdleTimeout),
+	}
+	if config.CertFile != "" {
+		srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		if config.DisableHTTP2 {
+			srv.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+		}
+	}
+	return srv
+}
+
+// listenAndServe serves until the server fails or the process is asked to stop. In-flight
+// requests are given the shutdown timeout to complete before shutdown is called.
+func listenAndServe(srv *http.Server, config wallie.Server, shutdown func()) error {
+	errc := make(chan error, 1)
+	go func() {
+		if config.CertFile != "" {
+			errc <- srv.ListenAndServeTLS(config.CertFile, config.KeyFile)
+			return
+		}
+		errc <- srv.ListenAndServe()
+	}()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(sigs)
+
+	select {
+	case err := <-errc:
+		return err
+	case sig := <-sigs:
+		log.Printf("received %v, waiting for requests to complete", sig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), seconds(config.ShutdownTimeoutSeconds, defaultShutdownTimeout))
+	defer cancel()
+	err := srv.Shutdown(ctx)
+	shutdown()
+	if err != nil {
+		return err
+	}
+	<-errc
+	log.Println("shutdown complete")
+
+	return nil
+}
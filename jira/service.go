@@ -0,0 +1,183 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/share"
+)
+
+// readOnlyPaths are the views which can be opened with a share token in place of a session.
+var readOnlyPaths = map[string]bool{
+	"/flow":      true,
+	"/kanban":    true,
+	"/wallboard": true,
+}
+
+// serviceSession is how long a service account session is reused before logging in again.
+const serviceSession = 30 * time.Minute
+
+// ErrNoServiceAccount is returned by a service account without credentials, the read-only
+// views then need a session of their own.
+var ErrNoServiceAccount = errors.New("no Jira service account is configured")
+
+// isShared returns true when req reads a read-only view with a valid share token. Share
+// tokens are ignored without a service account to read Jira as.
+func isShared(req *http.Request, config wallie.Config) bool {
+	if config.ServiceUser == "" {
+		return false
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	if !readOnlyPaths[req.URL.Path] {
+		return false
+	}
+	return share.Verify(config.ShareSecret, req.URL, time.Now()) == nil
+}
+
+// ServiceAccount is the Jira session shared by the read-only views. The credentials come
+// from ServiceUser and ServicePassword, which JIRA_SERVICE_USER and JIRA_SERVICE_PASSWORD
+// override. The personal credentials of the command-line are never used.
+type ServiceAccount struct {
+	config wallie.Config
+
+	sync.Mutex
+	cookies []*http.Cookie
+	expires time.Time
+}
+
+// NewServiceAccount creates a service account for config, it logs in on first use.
+func NewServiceAccount(config wallie.Config) *ServiceAccount {
+	return &ServiceAccount{config: config}
+}
+
+// Client returns a read-only client authenticated as the service account. The session is
+// reused until it expires or Jira rejects it.
+func (s *ServiceAccount) Client() (project.Client, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.cookies == nil || time.Now().After(s.expires) {
+		cookies, err := s.login()
+		if err != nil {
+			return nil, err
+		}
+		s.cookies = cookies
+		s.expires = time.Now().Add(serviceSession)
+	}
+
+	c := &CookieClient{Config: s.config, Cookies: s.cookies}
+	return project.ReadOnly(serviceClient{c, s}), nil
+}
+
+func (s *ServiceAccount) login() ([]*http.Cookie, error) {
+	if s.config.ServiceUser == "" {
+		return nil, ErrNoServiceAccount
+	}
+	return Authenticate(s.config, s.config.ServiceUser, s.config.ServicePassword)
+}
+
+// forget discards the session when err shows Jira rejected it so the next client logs in again.
+func (s *ServiceAccount) forget(err error) {
+	if !errors.Is(err, ErrUnauthorized) {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.cookies = nil
+}
+
+// serviceClient is a client of the service account which forgets the session when Jira
+// rejects it.
+type serviceClient struct {
+	*CookieClient
+	account *ServiceAccount
+}
+
+func (c serviceClient) WithContext(ctx context.Context) project.Client {
+	return serviceClient{c.CookieClient.WithContext(ctx).(*CookieClient), c.account}
+}
+
+func (c serviceClient) ListStories(projectID string) (project.Backlog, error) {
+	backlog, err := c.CookieClient.ListStories(projectID)
+	c.account.forget(err)
+	return backlog, err
+}
+
+func (c serviceClient) ListCompleted(projectID string, since time.Time) ([]project.Completed, error) {
+	completed, err := c.CookieClient.ListCompleted(projectID, since)
+	c.account.forget(err)
+	return completed, err
+}
+
+func (c serviceClient) GetStory(projectID, id string) (project.Story, error) {
+	story, err := c.CookieClient.GetStory(projectID, id)
+	c.account.forget(err)
+	return story, err
+}
+
+func (c serviceClient) User() (string, error) {
+	user, err := c.CookieClient.User()
+	c.account.forget(err)
+	return user, err
+}
+
+func (c serviceClient) CanEdit(projectID string) (bool, error) {
+	ok, err := c.CookieClient.CanEdit(projectID)
+	c.account.forget(err)
+	return ok, err
+}
+
+// ReadOnly returns a client for a read-only view, viewers with a session of their own use
+// it and everyone else uses the service account.
+func (s *ServiceAccount) ReadOnly(config wallie.Config, cookies []*http.Cookie) project.Client {
+	for _, c := range cookies {
+		if c.Name == config.SessionName {
+			return project.ReadOnly(New(config, cookies))
+		}
+	}
+
+	c, err := s.Client()
+	if err != nil {
+		return failedClient{err}
+	}
+	return c
+}
+
+// failedClient reports err for every call, it stands in when the service account cannot log in.
+type failedClient struct {
+	err error
+}
+
+func (c failedClient) ListStories(projectID string) (project.Backlog, error) {
+	return project.Backlog{}, c.err
+}
+
+func (c failedClient) ListCompleted(projectID string, since time.Time) ([]project.Completed, error) {
+	return nil, c.err
+}
+
+func (c failedClient) GetStory(projectID, id string) (project.Story, error) {
+	return project.Story{}, c.err
+}
+
+func (c failedClient) CreateStory(projectID, title, description, size string) (project.Story, error) {
+	return project.Story{}, c.err
+}
+
+func (c failedClient) UpdateStory(projectID, id, title, description, size string) error {
+	return c.err
+}
+
+func (c failedClient) User() (string, error) {
+	return "", c.err
+}
+
+func (c failedClient) CanEdit(projectID string) (bool, error) {
+	return false, c.err
+}
@@ -0,0 +1,134 @@
+package jira_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/jira"
+	"github.com/nfisher/wallie/share"
+)
+
+func Test_ServiceAccount_session(t *testing.T) {
+	var logins, revoked int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/rest/auth/1/session":
+			atomic.AddInt32(&logins, 1)
+			atomic.StoreInt32(&revoked, 0)
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "abc"})
+			w.Write([]byte(`{}`))
+		case "/rest/api/2/search":
+			if atomic.LoadInt32(&revoked) == 1 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"total":0,"issues":[]}`))
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer srv.Close()
+
+	account := jira.NewServiceAccount(wallie.Config{JiraBase: srv.URL, SessionName: "JSESSIONID", ServiceUser: "service", ServicePassword: "secret"})
+
+	for i := 0; i < 3; i++ {
+		c, err := account.Client()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = c.ListStories("ABC")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&logins); n != 1 {
+		t.Errorf("got %v logins, want 1 for a cached session", n)
+	}
+
+	atomic.StoreInt32(&revoked, 1)
+	c, err := account.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.ListStories("ABC")
+	if !errors.Is(err, jira.ErrUnauthorized) {
+		t.Fatalf("got err %v, want ErrUnauthorized", err)
+	}
+
+	c, err = account.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.ListStories("ABC")
+	if err != nil {
+		t.Fatalf("got err %v after the session was revoked, want a new session", err)
+	}
+	if n := atomic.LoadInt32(&logins); n != 2 {
+		t.Errorf("got %v logins, want 2 after the session was revoked", n)
+	}
+}
+
+func Test_ServiceAccount_requires_credentials(t *testing.T) {
+	var logins int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&logins, 1)
+		http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "abc"})
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	// the personal credentials of the command-line must not be used by anonymous viewers.
+	t.Setenv("JIRA_SESSION", "personal")
+	t.Setenv("JIRA_USER", "alice")
+	t.Setenv("JIRA_PASSWORD", "secret")
+	account := jira.NewServiceAccount(wallie.Config{JiraBase: srv.URL, SessionName: "JSESSIONID"})
+
+	_, err := account.Client()
+	if !errors.Is(err, jira.ErrNoServiceAccount) {
+		t.Errorf("got err %v, want %v", err, jira.ErrNoServiceAccount)
+	}
+	if n := atomic.LoadInt32(&logins); n != 0 {
+		t.Errorf("got %v logins, want none", n)
+	}
+}
+
+func Test_RequireLogin_shared(t *testing.T) {
+	t.Parallel()
+	config := wallie.Config{SessionName: "JSESSIONID", LoginPath: "/login", ShareSecret: "secret"}
+	link, err := share.URL(config.ShareSecret, "/kanban", "ABC", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	td := []struct {
+		name        string
+		serviceUser string
+		status      int
+	}{
+		{"service account", "service", http.StatusNoContent},
+		{"no service account", "", http.StatusOK},
+	}
+
+	for _, tc := range td {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			config := config
+			config.ServiceUser = tc.serviceUser
+			h := jira.RequireLogin(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}), config)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, link, nil))
+			if w.Code != tc.status {
+				t.Errorf("got status = %v, want %v", w.Code, tc.status)
+			}
+		})
+	}
+}
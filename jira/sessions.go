@@ -0,0 +1,76 @@
+package jira
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nfisher/wallie"
+)
+
+// sessionTTL matches the lifetime of the session cookies set at login.
+const sessionTTL = time.Hour
+
+// minSweep is the number of sessions remembered before expired sessions are swept.
+const minSweep = 64
+
+// sessionUsers remembers who each Jira session belongs to so requests can be logged with
+// the user without asking Jira.
+type sessionUsers struct {
+	mu    sync.Mutex
+	users map[string]sessionUser
+	// sweepAt is the number of sessions at which expired sessions are next swept.
+	sweepAt int
+}
+
+type sessionUser struct {
+	name    string
+	expires time.Time
+}
+
+var sessions = &sessionUsers{users: make(map[string]sessionUser)}
+
+func (s *sessionUsers) remember(session, user string, now time.Time) {
+	if session == "" || user == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.users) >= s.sweepAt {
+		for k, u := range s.users {
+			if now.After(u.expires) {
+				delete(s.users, k)
+			}
+		}
+		s.sweepAt = 2*len(s.users) + minSweep
+	}
+	s.users[session] = sessionUser{name: user, expires: now.Add(sessionTTL)}
+}
+
+func (s *sessionUsers) lookup(session string, now time.Time) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[session]
+	if !ok || now.After(u.expires) {
+		return ""
+	}
+	return u.name
+}
+
+func sessionID(config wallie.Config, cookies []*http.Cookie) string {
+	for _, c := range cookies {
+		if c.Name == config.SessionName {
+			return c.Value
+		}
+	}
+	return ""
+}
+
+// SessionUser returns a func which names the user whose session made a request, it
+// returns an empty string for sessions which haven't logged in or looked up their user
+// since the server started.
+func SessionUser(config wallie.Config) func(*http.Request) string {
+	return func(req *http.Request) string {
+		return sessions.lookup(sessionID(config, req.Cookies()), time.Now())
+	}
+}
@@ -0,0 +1,46 @@
+package jira_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/jira"
+)
+
+func Test_Login_session_user(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/rest/auth/1/session" {
+			http.NotFound(w, req)
+			return
+		}
+		if req.Method == http.MethodPost {
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "login-session"})
+			w.Write([]byte(`{"session":{"name":"JSESSIONID","value":"login-session"}}`))
+			return
+		}
+		w.Write([]byte(`{"name":"nfisher"}`))
+	}))
+	defer srv.Close()
+
+	config := wallie.Config{JiraBase: srv.URL, SessionName: "JSESSIONID", LoginPath: "/login", LandingPath: "/tshirt"}
+	form := url.Values{"email": {"nathan@example.com"}, "password": {"secret"}}
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	jira.Login(config)(w, req)
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("got status = %v, want 303: %s", w.Code, w.Body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/tshirt", nil)
+	req.AddCookie(&http.Cookie{Name: "JSESSIONID", Value: "login-session"})
+	got := jira.SessionUser(config)(req)
+	if got != "nfisher" {
+		t.Errorf("got session user %q, want the Jira name nfisher", got)
+	}
+}
@@ -0,0 +1,276 @@
+// Package metrics records counters and histograms and exposes them in the Prometheus
+// text format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Path is the path metrics are served from.
+const Path = "/metrics"
+
+// DefBuckets are the upper bounds in seconds of the buckets used for latencies.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Default is the registry the application metrics are recorded in.
+var Default = NewRegistry()
+
+// Handler serves the metrics of the default registry.
+func Handler() http.Handler {
+	return Default
+}
+
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+// Registry holds a set of metrics which are written together.
+type Registry struct {
+	mu         sync.Mutex
+	names      map[string]bool
+	collectors []collector
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+func (r *Registry) register(name string, c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[name] {
+		panic("metrics: duplicate metric " + name)
+	}
+	r.names[name] = true
+	r.collectors = append(r.collectors, c)
+}
+
+// NewCounter registers a counter partitioned by the provided label names.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{
+		desc:   desc{name: name, help: help, labels: labels},
+		series: make(map[string]*counterSeries),
+	}
+	r.register(name, c)
+	return c
+}
+
+// NewHistogram registers a histogram with the provided bucket upper bounds partitioned by
+// the provided label names.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	h := &Histogram{
+		desc:    desc{name: name, help: help, labels: labels},
+		buckets: b,
+		series:  make(map[string]*histogramSeries),
+	}
+	r.register(name, h)
+	return h
+}
+
+// WriteTo writes all metrics in the registry in the Prometheus text format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteTo(w)
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+type desc struct {
+	name   string
+	help   string
+	labels []string
+}
+
+func (d desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", d.name, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+func (d desc) header(w *bufio.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, escape(d.help, false))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, kind)
+}
+
+// pairs formats the label values and any extra name value pairs as a label set.
+func (d desc) pairs(values []string, extra ...string) string {
+	if len(values) == 0 && len(extra) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(d.labels[i] + `="` + escape(v, true) + `"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		if sb.Len() > 1 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(extra[i] + `="` + extra[i+1] + `"`)
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func escape(s string, quote bool) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	if quote {
+		s = strings.Replace(s, `"`, `\"`, -1)
+	}
+	return s
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Counter is a value which only increases.
+type Counter struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	values []string
+	value  float64
+}
+
+// Inc adds one to the counter with the provided label values.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds v to the counter with the provided label values, negative values are ignored.
+func (c *Counter) Add(v float64, values ...string) {
+	if v < 0 {
+		return
+	}
+	k := c.key(values)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.series[k]
+	if !ok {
+		s = &counterSeries{values: append([]string(nil), values...)}
+		c.series[k] = s
+	}
+	s.value += v
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.header(w, "counter")
+	keys := make([]string, 0, len(c.series))
+	for k := range c.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := c.series[k]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.pairs(s.values), formatFloat(s.value))
+	}
+}
+
+// Histogram counts observations in buckets.
+type Histogram struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	values []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Observe adds v to the histogram with the provided label values.
+func (h *Histogram) Observe(v float64, values ...string) {
+	k := h.key(values)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[k]
+	if !ok {
+		s = &histogramSeries{
+			values: append([]string(nil), values...),
+			counts: make([]uint64, len(h.buckets)),
+		}
+		h.series[k] = s
+	}
+	i := sort.SearchFloat64s(h.buckets, v)
+	if i < len(s.counts) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.header(w, "histogram")
+	keys := make([]string, 0, len(h.series))
+	for k := range h.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := h.series[k]
+		var cumulative uint64
+		for i, le := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.pairs(s.values, "le", formatFloat(le)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.pairs(s.values, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.pairs(s.values), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.pairs(s.values), s.count)
+	}
+}
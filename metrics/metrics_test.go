@@ -0,0 +1,93 @@
+package metrics_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie/metrics"
+)
+
+func Test_Counter(t *testing.T) {
+	t.Parallel()
+	r := metrics.NewRegistry()
+	c := r.NewCounter("requests_total", "Requests served.", "route", "status")
+	c.Inc("/tshirt", "200")
+	c.Inc("/tshirt", "200")
+	c.Add(3, `/a"b\c`, "500")
+	c.Add(-1, "/tshirt", "200")
+
+	var buf bytes.Buffer
+	_, err := r.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/a\"b\\c",status="500"} 3
+requests_total{route="/tshirt",status="200"} 2
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func Test_Histogram(t *testing.T) {
+	t.Parallel()
+	r := metrics.NewRegistry()
+	h := r.NewHistogram("latency_seconds", "Latency.", []float64{1, 0.5})
+	h.Observe(0.25)
+	h.Observe(0.5)
+	h.Observe(0.75)
+	h.Observe(2)
+
+	var buf bytes.Buffer
+	_, err := r.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.5"} 2
+latency_seconds_bucket{le="1"} 3
+latency_seconds_bucket{le="+Inf"} 4
+latency_seconds_sum 3.5
+latency_seconds_count 4
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func Test_ServeHTTP(t *testing.T) {
+	t.Parallel()
+	r := metrics.NewRegistry()
+	r.NewCounter("pages_total", "Pages fetched.").Inc()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, metrics.Path, nil))
+
+	ct := w.Header().Get("Content-Type")
+	if !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("got Content-Type %q, want text/plain; version=0.0.4", ct)
+	}
+	if !strings.Contains(w.Body.String(), "\npages_total 1\n") {
+		t.Errorf("got body %q, want pages_total 1", w.Body.String())
+	}
+}
+
+func Test_duplicate(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if recover() == nil {
+			t.Error("got no panic, want panic for duplicate metric")
+		}
+	}()
+	r := metrics.NewRegistry()
+	r.NewCounter("dup_total", "Duplicate.")
+	r.NewHistogram("dup_total", "Duplicate.", metrics.DefBuckets)
+}
@@ -0,0 +1,100 @@
+package pdf
+
+import (
+	"strings"
+)
+
+// winAnsi maps the characters of WinAnsiEncoding outside of Latin-1 to their code.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// encode converts s to WinAnsiEncoding replacing characters it cannot represent with '?'.
+func encode(s string) []byte {
+	var b []byte
+	for _, r := range s {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			b = append(b, ' ')
+		case r >= 32 && r < 127 || r >= 0xA0 && r <= 0xFF:
+			b = append(b, byte(r))
+		default:
+			c, ok := winAnsi[r]
+			if !ok {
+				c = '?'
+			}
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+// widths of the printable ASCII characters from space to tilde in 1/1000 of the font size.
+var widths = [...][95]int{
+	Helvetica: {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	HelveticaBold: {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// TextWidth returns the width of s in points when drawn in font at size.
+func TextWidth(font Font, size float64, s string) float64 {
+	var total int
+	for _, c := range encode(s) {
+		if c >= 32 && c < 127 {
+			total += widths[font][c-32]
+		} else {
+			// characters outside of ASCII are approximated by the width of a lower case letter.
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Wrap breaks s into lines no wider than width, words longer than a line are split.
+func Wrap(font Font, size, width float64, s string) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if TextWidth(font, size, candidate) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = word
+		for TextWidth(font, size, line) > width {
+			r := []rune(line)
+			n := len(r) - 1
+			for n > 1 && TextWidth(font, size, string(r[:n])) > width {
+				n--
+			}
+			lines = append(lines, string(r[:n]))
+			line = string(r[n:])
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
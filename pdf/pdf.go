@@ -0,0 +1,152 @@
+// Package pdf writes simple PDF documents of filled rectangles and Helvetica text.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Size is the width and height of a page in points.
+type Size struct {
+	Width  float64
+	Height float64
+}
+
+// Standard paper sizes.
+var (
+	A4     = Size{595.28, 841.89}
+	Letter = Size{612, 792}
+)
+
+// Font is one of the standard PDF fonts.
+type Font int
+
+// Fonts available to every PDF reader without embedding.
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+// Document is a PDF document under construction, coordinates are in points with the
+// origin in the top left corner of the page.
+type Document struct {
+	size  Size
+	pages []*bytes.Buffer
+}
+
+// New returns an empty document with pages of size.
+func New(size Size) *Document {
+	return &Document{size: size}
+}
+
+// Size returns the page size of the document.
+func (d *Document) Size() Size {
+	return d.size
+}
+
+// AddPage starts a new page, subsequent drawing is on that page.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Rect fills a black rectangle with its top left corner at x, y.
+func (d *Document) Rect(x, y, w, h float64) {
+	fmt.Fprintf(d.page(), "%s %s %s %s re f\n", num(x), num(d.size.Height-y-h), num(w), num(h))
+}
+
+// StrokeRect outlines a rectangle with its top left corner at x, y.
+func (d *Document) StrokeRect(x, y, w, h, lineWidth float64) {
+	fmt.Fprintf(d.page(), "%s w %s %s %s %s re S\n", num(lineWidth), num(x), num(d.size.Height-y-h), num(w), num(h))
+}
+
+// Text draws s with its baseline starting at x, y.
+func (d *Document) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(d.page(), "BT /F%d %s Tf %s %s Td (%s) Tj ET\n", font+1, num(size), num(x), num(d.size.Height-y), escape(encode(s)))
+}
+
+// WriteTo writes the document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(format string, a ...interface{}) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", len(offsets))
+		fmt.Fprintf(&buf, format, a...)
+		buf.WriteString("\nendobj\n")
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// objects 1 to 4 are the catalog, page tree and fonts, each page is followed by its content.
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+i*2))
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, p := range d.pages {
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(d.size.Width), num(d.size.Height), 6+i*2)
+
+		var content bytes.Buffer
+		zw := zlib.NewWriter(&content)
+		_, err := zw.Write(p.Bytes())
+		if err != nil {
+			return 0, err
+		}
+		err = zw.Close()
+		if err != nil {
+			return 0, err
+		}
+		object("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", content.Len(), content.Bytes())
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+// num formats a coordinate without trailing zeros.
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+func escape(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		switch {
+		case c == '\\' || c == '(' || c == ')':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c < 32 || c > 126:
+			fmt.Fprintf(&sb, "\\%03o", c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
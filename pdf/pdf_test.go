@@ -0,0 +1,100 @@
+package pdf_test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie/pdf"
+)
+
+func Test_WriteTo_xref_offsets(t *testing.T) {
+	t.Parallel()
+	doc := pdf.New(pdf.A4)
+	doc.AddPage()
+	doc.Text(10, 20, pdf.HelveticaBold, 12, "DMP-1 (draft)")
+	doc.AddPage()
+	doc.Rect(10, 10, 5, 5)
+
+	var buf bytes.Buffer
+	_, err := doc.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	b := buf.Bytes()
+
+	if !bytes.HasPrefix(b, []byte("%PDF-1.4\n")) {
+		t.Errorf("got prefix %q, want %%PDF-1.4", b[:9])
+	}
+	if !bytes.Contains(b, []byte("/Count 2")) {
+		t.Errorf("got no /Count 2, want 2 pages")
+	}
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(b)
+	if m == nil {
+		t.Fatalf("got no startxref trailer")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(b[xref:], []byte("xref\n")) {
+		t.Fatalf("got %q at startxref, want xref", b[xref:xref+4])
+	}
+
+	entries := strings.Split(string(b[xref:]), "\n")[3:]
+	for i := 1; i <= 8; i++ {
+		off, _ := strconv.Atoi(entries[i-1][:10])
+		want := fmt.Sprintf("%d 0 obj", i)
+		if !bytes.HasPrefix(b[off:], []byte(want)) {
+			t.Errorf("got %q at object %v offset, want %q", b[off:off+len(want)], i, want)
+		}
+	}
+}
+
+func Test_TextWidth(t *testing.T) {
+	t.Parallel()
+	td := []struct {
+		font pdf.Font
+		text string
+		want float64
+	}{
+		{pdf.Helvetica, "", 0},
+		{pdf.Helvetica, "il", 4.44},
+		{pdf.HelveticaBold, "il", 5.56},
+		{pdf.Helvetica, "é", 5.56},
+	}
+
+	for _, tc := range td {
+		got := pdf.TextWidth(tc.font, 10, tc.text)
+		if fmt.Sprintf("%.2f", got) != fmt.Sprintf("%.2f", tc.want) {
+			t.Errorf("TextWidth(%q) got %.2f, want %.2f", tc.text, got, tc.want)
+		}
+	}
+}
+
+func Test_Wrap(t *testing.T) {
+	t.Parallel()
+	td := []struct {
+		name  string
+		width float64
+		text  string
+		want  []string
+	}{
+		{"empty", 100, "", nil},
+		{"fits", 100, "fix login", []string{"fix login"}},
+		{"breaks on words", 30, "fix the login", []string{"fix the", "login"}},
+		{"splits long words", 23, "aaaaaaaa", []string{"aaaa", "aaaa"}},
+	}
+
+	for _, tc := range td {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got := pdf.Wrap(pdf.Helvetica, 10, tc.width, tc.text)
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
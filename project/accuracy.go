@@ -0,0 +1,176 @@
+package project
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Completed is a story that has been completed and how long it took.
+type Completed struct {
+	Story
+	CycleTime time.Duration `json:"cycleTime"`
+	Resolved  time.Time     `json:"resolved"`
+}
+
+// Days returns the cycle time in days.
+func (c Completed) Days() float64 {
+	return c.CycleTime.Hours() / 24.0
+}
+
+// AccuracyReport compares the estimated size of completed stories with their cycle time.
+type AccuracyReport struct {
+	Project       string          `json:"project"`
+	Since         time.Time       `json:"since"`
+	Distributions []*Distribution `json:"distributions"`
+	Overlaps      []Overlap       `json:"overlaps"`
+}
+
+// Count returns the number of completed stories in the report.
+func (r AccuracyReport) Count() int {
+	var n int
+	for _, d := range r.Distributions {
+		n += d.Count
+	}
+	return n
+}
+
+// Distribution is the box plot data of the cycle time in days for a single size.
+type Distribution struct {
+	Size     Size        `json:"size"`
+	Count    int         `json:"count"`
+	Min      float64     `json:"min"`
+	Q1       float64     `json:"q1"`
+	Median   float64     `json:"median"`
+	Q3       float64     `json:"q3"`
+	Max      float64     `json:"max"`
+	Outliers []Completed `json:"outliers"`
+	// Plot is the box plot data as a percentage of the longest cycle time in the report.
+	Plot Box `json:"-"`
+}
+
+// Box is the five number summary of a distribution.
+type Box struct {
+	Min    float64 `json:"min"`
+	Q1     float64 `json:"q1"`
+	Median float64 `json:"median"`
+	Q3     float64 `json:"q3"`
+	Max    float64 `json:"max"`
+}
+
+// IQR returns the interquartile range.
+func (b Box) IQR() float64 {
+	return b.Q3 - b.Q1
+}
+
+// Overlap is the share of the interquartile ranges two adjacent sizes have in common.
+// A ratio of 0 means the sizes are clearly distinct and 1 means they are indistinguishable.
+type Overlap struct {
+	Smaller Size    `json:"smaller"`
+	Larger  Size    `json:"larger"`
+	Ratio   float64 `json:"ratio"`
+}
+
+// Percent returns the ratio as a percentage.
+func (o Overlap) Percent() int {
+	return int(math.Round(o.Ratio * 100))
+}
+
+// NewAccuracyReport groups the completed stories by size in scale order and calculates
+// the cycle time distribution of each size. Unsized stories are ignored.
+func NewAccuracyReport(projectID string, since time.Time, scale Scale, stories []Completed) AccuracyReport {
+	report := AccuracyReport{
+		Project: projectID,
+		Since:   since,
+	}
+
+	m := make(map[Size][]Completed)
+	for _, v := range stories {
+		m[v.Size] = append(m[v.Size], v)
+	}
+
+	var longest float64
+	for _, sz := range scale.Sizes() {
+		d := distribution(sz, m[sz])
+		if d.Max > longest {
+			longest = d.Max
+		}
+		report.Distributions = append(report.Distributions, d)
+	}
+
+	for _, d := range report.Distributions {
+		if longest > 0 && d.Count > 0 {
+			d.Plot = Box{
+				Min:    100 * d.Min / longest,
+				Q1:     100 * d.Q1 / longest,
+				Median: 100 * d.Median / longest,
+				Q3:     100 * d.Q3 / longest,
+				Max:    100 * d.Max / longest,
+			}
+		}
+	}
+
+	dd := report.Distributions
+	for i := 1; i < len(dd); i++ {
+		if dd[i-1].Count == 0 || dd[i].Count == 0 {
+			continue
+		}
+		report.Overlaps = append(report.Overlaps, Overlap{
+			Smaller: dd[i-1].Size,
+			Larger:  dd[i].Size,
+			Ratio:   overlap(dd[i-1], dd[i]),
+		})
+	}
+
+	return report
+}
+
+func distribution(size Size, stories []Completed) *Distribution {
+	d := &Distribution{Size: size, Count: len(stories)}
+	if len(stories) == 0 {
+		return d
+	}
+
+	days := make([]float64, len(stories))
+	for i, v := range stories {
+		days[i] = v.Days()
+	}
+	sort.Float64s(days)
+
+	d.Min = days[0]
+	d.Q1 = quantile(days, 0.25)
+	d.Median = quantile(days, 0.5)
+	d.Q3 = quantile(days, 0.75)
+	d.Max = days[len(days)-1]
+
+	iqr := d.Q3 - d.Q1
+	lower := d.Q1 - 1.5*iqr
+	upper := d.Q3 + 1.5*iqr
+	for _, v := range stories {
+		if v.Days() < lower || v.Days() > upper {
+			d.Outliers = append(d.Outliers, v)
+		}
+	}
+
+	return d
+}
+
+// quantile returns the q quantile of the sorted values using linear interpolation.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
+
+func overlap(a, b *Distribution) float64 {
+	union := math.Max(a.Q3, b.Q3) - math.Min(a.Q1, b.Q1)
+	if union == 0 {
+		return 1.0
+	}
+	common := math.Min(a.Q3, b.Q3) - math.Max(a.Q1, b.Q1)
+	if common < 0 {
+		return 0.0
+	}
+	return common / union
+}
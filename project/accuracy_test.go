@@ -0,0 +1,130 @@
+package project_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/project/projecttest"
+)
+
+func completed(size project.Size, days ...float64) []project.Completed {
+	var cc []project.Completed
+	for _, d := range days {
+		cc = append(cc, project.Completed{
+			Story:     project.Story{Size: size},
+			CycleTime: time.Duration(d * 24 * float64(time.Hour)),
+		})
+	}
+	return cc
+}
+
+func Test_NewAccuracyReport(t *testing.T) {
+	t.Parallel()
+
+	var stories []project.Completed
+	stories = append(stories, completed(project.Small, 1, 2, 3, 4, 30)...)
+	stories = append(stories, completed(project.Medium, 3, 4, 5, 6, 7)...)
+	stories = append(stories, completed(project.Unsized, 100)...)
+
+	report := project.NewAccuracyReport("ABC", time.Now(), project.TShirt, stories)
+
+	if len(report.Distributions) != 6 {
+		t.Fatalf("got len(Distributions) = %v, want 6", len(report.Distributions))
+	}
+
+	if report.Count() != 10 {
+		t.Errorf("got Count() = %v, want 10", report.Count())
+	}
+
+	small := report.Distributions[1]
+	td := []struct {
+		name     string
+		actual   float64
+		expected float64
+	}{
+		{"min", small.Min, 1},
+		{"q1", small.Q1, 2},
+		{"median", small.Median, 3},
+		{"q3", small.Q3, 4},
+		{"max", small.Max, 30},
+		{"plot max", small.Plot.Max, 100},
+	}
+	for _, tc := range td {
+		if tc.actual != tc.expected {
+			t.Errorf("got %v = %v, want %v", tc.name, tc.actual, tc.expected)
+		}
+	}
+
+	if len(small.Outliers) != 1 {
+		t.Errorf("got len(Outliers) = %v, want 1", len(small.Outliers))
+	}
+
+	if len(report.Overlaps) != 1 {
+		t.Fatalf("got len(Overlaps) = %v, want 1", len(report.Overlaps))
+	}
+
+	// S IQR [2,4] and M IQR [4,6] touch at a single point.
+	if report.Overlaps[0].Ratio != 0.0 {
+		t.Errorf("got overlap = %v, want 0", report.Overlaps[0].Ratio)
+	}
+}
+
+func Test_AccuracyHandler_project(t *testing.T) {
+	t.Parallel()
+	fn := func(wallie.Config, []*http.Cookie) project.Client { return &projecttest.Client{} }
+	h := project.AccuracyHandler(fn, wallie.Config{})
+
+	td := []struct {
+		name    string
+		project string
+		status  int
+	}{
+		{"valid", "ABC", http.StatusOK},
+		{"empty", "", http.StatusNotFound},
+		{"quoted jql", `ABC" OR project = "XYZ`, http.StatusNotFound},
+	}
+
+	for _, tc := range td {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			w := httptest.NewRecorder()
+			h(w, httptest.NewRequest(http.MethodGet, "/accuracy?project="+url.QueryEscape(tc.project), nil))
+			if w.Code != tc.status {
+				t.Errorf("got status = %v, want %v", w.Code, tc.status)
+			}
+		})
+	}
+}
+
+func Test_Handlers_invalid_project(t *testing.T) {
+	t.Parallel()
+	fn := func(wallie.Config, []*http.Cookie) project.Client { return &projecttest.Client{} }
+
+	td := map[string]http.HandlerFunc{
+		"/tshirt":        project.TshirtHandler(fn, wallie.Config{}, &projecttest.History{}, noExemplars{}),
+		"/accuracy":      project.AccuracyHandler(fn, wallie.Config{}),
+		"/similar/index": project.IndexHandler(fn, wallie.Config{}, nil),
+		"/export":        project.ExportHandler(fn, wallie.Config{}),
+		"/print":         project.PrintHandler(fn, wallie.Config{}),
+		"/kanban":        project.KanbanHandler(fn, wallie.Config{}),
+		"/import":        project.ImportHandler(fn, wallie.Config{}),
+	}
+
+	for path, h := range td {
+		path, h := path, h
+		t.Run(path, func(t *testing.T) {
+			t.Parallel()
+			w := httptest.NewRecorder()
+			h(w, httptest.NewRequest(http.MethodPost, path+"?project="+url.QueryEscape(`ABC" OR project = "XYZ`), nil))
+			if w.Code != http.StatusNotFound {
+				t.Errorf("got status = %v, want 404", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/nfisher/wallie"
+)
+
+// APIPrefix is the path prefix of the versioned JSON API.
+const APIPrefix = "/api/v1/"
+
+// APIError is the body of every unsuccessful API response.
+type APIError struct {
+	Error APIErrorDetail `json:"error"`
+}
+
+// APIErrorDetail describes why an API request failed.
+type APIErrorDetail struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// StoryPatch is the body of a PATCH request to a story, absent fields are left unchanged.
+type StoryPatch struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	Size        *Size   `json:"size"`
+}
+
+// APIHandler serves the JSON API for project backlogs and stories:
+//
+//	GET   /api/v1/projects/{key}/stories
+//	GET   /api/v1/projects/{key}/groups
+//	GET   /api/v1/projects/{key}/stories/{id}
+//	PATCH /api/v1/projects/{key}/stories/{id}
+func APIHandler(fn func(wallie.Config, []*http.Cookie) Client, config wallie.Config, history History) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		projectID, resource, id, ok := parseAPIPath(req.URL.Path)
+		if !ok {
+			WriteError(w, http.StatusNotFound, "resource not found")
+			return
+		}
+
+		client := clientFor(fn, config, req)
+
+		switch {
+		case resource == "stories" && id == "":
+			if !allowMethods(w, req, http.MethodGet) {
+				return
+			}
+			backlog, err := client.ListStories(projectID)
+			if err != nil {
+				WriteError(w, http.StatusBadGateway, err.Error())
+				return
+			}
+			WriteJSON(w, http.StatusOK, &backlog)
+
+		case resource == "groups" && id == "":
+			if !allowMethods(w, req, http.MethodGet) {
+				return
+			}
+			backlog, err := client.ListStories(projectID)
+			if err != nil {
+				WriteError(w, http.StatusBadGateway, err.Error())
+				return
+			}
+			WriteJSON(w, http.StatusOK, backlog.BySize())
+
+		case resource == "stories":
+			if !allowMethods(w, req, http.MethodGet, http.MethodPatch) {
+				return
+			}
+			if req.Method == http.MethodPatch {
+				perms, err := PermissionsFor(client, config, projectID)
+				if err != nil {
+					WriteError(w, http.StatusBadGateway, err.Error())
+					return
+				}
+				if !perms.Estimate {
+					WriteError(w, http.StatusForbidden, ErrForbidden.Error())
+					return
+				}
+				patchStory(w, req, client, config, history, projectID, id)
+				return
+			}
+			story, err := client.GetStory(projectID, id)
+			if err != nil {
+				WriteError(w, http.StatusBadGateway, err.Error())
+				return
+			}
+			WriteJSON(w, http.StatusOK, &story)
+
+		default:
+			WriteError(w, http.StatusNotFound, "resource not found")
+		}
+	}
+}
+
+func patchStory(w http.ResponseWriter, req *http.Request, client Client, config wallie.Config, history History, projectID, id string) {
+	var patch StoryPatch
+	err := json.NewDecoder(req.Body).Decode(&patch)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid story patch: "+err.Error())
+		return
+	}
+
+	story, err := Resize(client, history, ScaleFor(config, projectID), projectID, id, patch, sessionID(req, config.SessionName), req.FormValue("room"))
+	if errors.Is(err, ErrUnknownSize) {
+		WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, &story)
+}
+
+// ErrInvalidProject is returned when a project ID can't be a Jira project key.
+var ErrInvalidProject = errors.New("invalid project ID")
+
+var validAPIProject = regexp.MustCompile(`^\w+$`)
+var validAPIStory = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*-[0-9]+$`)
+
+// RequestProject returns the project a request is for from its API path or its project
+// parameter.
+func RequestProject(req *http.Request) string {
+	projectID, _, _, ok := parseAPIPath(req.URL.Path)
+	if ok {
+		return projectID
+	}
+	return req.URL.Query().Get("project")
+}
+
+// parseAPIPath splits /api/v1/projects/{key}/{resource}[/{id}] into its parts.
+func parseAPIPath(p string) (projectID, resource, id string, ok bool) {
+	if !strings.HasPrefix(p, APIPrefix+"projects/") {
+		return "", "", "", false
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(p, APIPrefix+"projects/"), "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || !validAPIProject.MatchString(parts[0]) {
+		return "", "", "", false
+	}
+
+	if len(parts) == 3 {
+		if !validAPIStory.MatchString(parts[2]) {
+			return "", "", "", false
+		}
+		id = parts[2]
+	}
+
+	return parts[0], parts[1], id, true
+}
+
+func allowMethods(w http.ResponseWriter, req *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if req.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+	return false
+}
+
+// AcceptsJSON returns true when the client prefers JSON over HTML.
+func AcceptsJSON(req *http.Request) bool {
+	for _, v := range strings.Split(req.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(v))
+		if err != nil {
+			continue
+		}
+		switch mt {
+		case "application/json":
+			return true
+		case "text/html", "application/xhtml+xml":
+			return false
+		}
+	}
+	return false
+}
+
+// WriteJSON writes v as the JSON body of the response with the provided status.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// WriteError writes a JSON error body with the provided status.
+func WriteError(w http.ResponseWriter, status int, message string) {
+	WriteJSON(w, status, &APIError{Error: APIErrorDetail{Status: status, Message: message}})
+}
+
+// render writes v as JSON when the client prefers it otherwise it executes the named template.
+func render(w http.ResponseWriter, req *http.Request, tmpl *template.Template, name string, v interface{}) {
+	if AcceptsJSON(req) {
+		WriteJSON(w, http.StatusOK, v)
+		return
+	}
+
+	err := ExecuteTemplate(req.Context(), tmpl, w, name, v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
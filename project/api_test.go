@@ -0,0 +1,208 @@
+package project_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/project/projecttest"
+)
+
+func apiFixture() (http.HandlerFunc, *projecttest.Client, *projecttest.History) {
+	client := &projecttest.Client{
+		Stories: []project.Story{
+			{ID: "ABC-1", Title: "Add login page", Size: project.Small},
+			{ID: "ABC-2", Title: "Create service skeleton"},
+		},
+	}
+	history := &projecttest.History{}
+	fn := func(wallie.Config, []*http.Cookie) project.Client { return client }
+	return project.APIHandler(fn, wallie.Config{}, history), client, history
+}
+
+func Test_API_stories(t *testing.T) {
+	t.Parallel()
+
+	h, _, _ := apiFixture()
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/api/v1/projects/ABC/stories", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status = %v, want 200", w.Code)
+	}
+
+	var backlog project.Backlog
+	err := json.NewDecoder(w.Body).Decode(&backlog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(backlog.Stories) != 2 {
+		t.Errorf("got len(stories) = %v, want 2", len(backlog.Stories))
+	}
+}
+
+func Test_API_patch(t *testing.T) {
+	t.Parallel()
+
+	h, client, history := apiFixture()
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodPatch, "/api/v1/projects/ABC/stories/ABC-2", strings.NewReader(`{"size":"L"}`)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status = %v, want 200: %s", w.Code, w.Body)
+	}
+
+	if client.Stories[1].Size != project.Large || client.Stories[1].Title != "Create service skeleton" {
+		t.Errorf("got %+v, want L with title unchanged", client.Stories[1])
+	}
+
+	if len(history.Changes) != 1 || history.Changes[0].From != "" || history.Changes[0].To != project.Large {
+		t.Errorf("got history %+v, want single change to L", history.Changes)
+	}
+}
+
+func Test_API_patch_history_failure(t *testing.T) {
+	t.Parallel()
+
+	h, client, history := apiFixture()
+	history.Err = errors.New("disk full")
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodPatch, "/api/v1/projects/ABC/stories/ABC-2", strings.NewReader(`{"size":"L"}`)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status = %v, want 200 as the story was updated: %s", w.Code, w.Body)
+	}
+
+	var story project.Story
+	err := json.NewDecoder(w.Body).Decode(&story)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if story.Size != project.Large || client.Stories[1].Size != project.Large {
+		t.Errorf("got %+v, want L", story)
+	}
+}
+
+func Test_API_errors(t *testing.T) {
+	t.Parallel()
+
+	td := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"unknown resource", http.MethodGet, "/api/v1/projects/ABC/epics", "", http.StatusNotFound},
+		{"invalid story", http.MethodGet, "/api/v1/projects/ABC/stories/nope", "", http.StatusNotFound},
+		{"wrong method", http.MethodDelete, "/api/v1/projects/ABC/stories", "", http.StatusMethodNotAllowed},
+		{"unknown size", http.MethodPatch, "/api/v1/projects/ABC/stories/ABC-1", `{"size":"XXXL"}`, http.StatusUnprocessableEntity},
+		{"invalid body", http.MethodPatch, "/api/v1/projects/ABC/stories/ABC-1", `{`, http.StatusBadRequest},
+	}
+
+	for _, tc := range td {
+		t.Run(tc.name, func(t *testing.T) {
+			h, _, _ := apiFixture()
+			w := httptest.NewRecorder()
+			h(w, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+
+			if w.Code != tc.status {
+				t.Errorf("got status = %v, want %v", w.Code, tc.status)
+			}
+
+			var apiErr project.APIError
+			err := json.NewDecoder(w.Body).Decode(&apiErr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if apiErr.Error.Status != tc.status {
+				t.Errorf("got error status = %v, want %v", apiErr.Error.Status, tc.status)
+			}
+		})
+	}
+}
+
+func Test_AcceptsJSON(t *testing.T) {
+	t.Parallel()
+
+	td := []struct {
+		accept   string
+		expected bool
+	}{
+		{"", false},
+		{"application/json", true},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", false},
+		{"application/json, text/plain, */*", true},
+	}
+
+	for _, tc := range td {
+		req := httptest.NewRequest(http.MethodGet, "/tshirt", nil)
+		req.Header.Set("Accept", tc.accept)
+		if project.AcceptsJSON(req) != tc.expected {
+			t.Errorf("got AcceptsJSON(%q) = %v, want %v", tc.accept, !tc.expected, tc.expected)
+		}
+	}
+}
+
+func Test_OpenAPI(t *testing.T) {
+	t.Parallel()
+
+	w := httptest.NewRecorder()
+	project.OpenAPIHandler(w, httptest.NewRequest(http.MethodGet, project.OpenAPIPath, nil))
+
+	var doc map[string]interface{}
+	err := json.NewDecoder(w.Body).Decode(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if doc["openapi"] != "3.0.2" {
+		t.Errorf("got openapi = %v, want 3.0.2", doc["openapi"])
+	}
+}
+
+func Test_Resize(t *testing.T) {
+	t.Parallel()
+
+	_, client, history := apiFixture()
+
+	size := project.Medium
+	story, err := project.Resize(client, history, project.TShirt, "ABC", "ABC-1", project.StoryPatch{Size: &size}, "cli", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if story.Size != project.Medium || story.Title != "Add login page" {
+		t.Errorf("got %+v, want M with title unchanged", story)
+	}
+	if len(history.Changes) != 1 || history.Changes[0].From != project.Small || history.Changes[0].User != "nfisher" {
+		t.Errorf("got history %+v, want single change from S by nfisher", history.Changes)
+	}
+
+	size = "XXXL"
+	_, err = project.Resize(client, history, project.TShirt, "ABC", "ABC-1", project.StoryPatch{Size: &size}, "cli", "")
+	if !errors.Is(err, project.ErrUnknownSize) {
+		t.Errorf("got err %v, want %v", err, project.ErrUnknownSize)
+	}
+}
+
+func Test_Resize_history_failure(t *testing.T) {
+	t.Parallel()
+
+	_, client, history := apiFixture()
+	history.Err = errors.New("disk full")
+
+	size := project.Large
+	story, err := project.Resize(client, history, project.TShirt, "ABC", "ABC-2", project.StoryPatch{Size: &size}, "cli", "")
+	if err != nil {
+		t.Fatalf("got err %v, want nil as the story was updated", err)
+	}
+	if story.Size != project.Large || client.Stories[1].Size != project.Large {
+		t.Errorf("got %+v, want L", story)
+	}
+}
@@ -0,0 +1,30 @@
+package project
+
+import "errors"
+
+// ErrNotExemplar is returned when a story which is unsized or not completed is pinned.
+var ErrNotExemplar = errors.New("only sized and completed stories can be pinned as exemplars")
+
+// Exemplars are completed stories pinned as a reference for each size of a project.
+type Exemplars interface {
+	Pin(projectID string, story Story) error
+	Unpin(projectID, id string) error
+	List(projectID string) ([]Story, error)
+}
+
+// ExemplarPage is the pinned exemplars of a project.
+type ExemplarPage struct {
+	Project   string  `json:"project"`
+	Exemplars []Story `json:"exemplars"`
+	Scale     Scale   `json:"scale"`
+}
+
+// Count returns the number of pinned exemplars.
+func (p ExemplarPage) Count() int {
+	return len(p.Exemplars)
+}
+
+// BySize returns a grouping of the exemplars by size.
+func (p ExemplarPage) BySize() []*Group {
+	return Backlog{Stories: p.Exemplars, Scale: p.Scale}.BySize()[1:]
+}
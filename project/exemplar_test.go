@@ -0,0 +1,64 @@
+package project_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/project/projecttest"
+)
+
+type memExemplars struct {
+	pinned []project.Story
+}
+
+func (e *memExemplars) Pin(projectID string, story project.Story) error {
+	e.pinned = append(e.pinned, story)
+	return nil
+}
+
+func (e *memExemplars) Unpin(projectID, id string) error               { return nil }
+func (e *memExemplars) List(projectID string) ([]project.Story, error) { return e.pinned, nil }
+
+func Test_ExemplarHandler_pin(t *testing.T) {
+	t.Parallel()
+	td := []struct {
+		name   string
+		story  project.Story
+		status int
+	}{
+		{"completed", project.Story{ID: "ABC-1", Size: project.Small, Status: "Done"}, http.StatusSeeOther},
+		{"closed", project.Story{ID: "ABC-1", Size: project.Small, Status: "closed"}, http.StatusSeeOther},
+		{"in progress", project.Story{ID: "ABC-1", Size: project.Small, Status: "In Progress"}, http.StatusBadRequest},
+		{"unsized", project.Story{ID: "ABC-1", Size: project.Unsized, Status: "Done"}, http.StatusBadRequest},
+	}
+
+	for _, tc := range td {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			client := &projecttest.Client{Stories: []project.Story{tc.story}}
+			fn := func(wallie.Config, []*http.Cookie) project.Client { return client }
+			exemplars := &memExemplars{}
+			h := project.ExemplarHandler(fn, wallie.Config{}, exemplars)
+
+			form := url.Values{"id": {"ABC-1"}}
+			req := httptest.NewRequest(http.MethodPost, "/exemplars?project=ABC", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			h(w, req)
+
+			if w.Code != tc.status {
+				t.Errorf("got status = %v, want %v: %s", w.Code, tc.status, w.Body)
+			}
+			pinned := len(exemplars.pinned) == 1
+			if pinned != (tc.status == http.StatusSeeOther) {
+				t.Errorf("got pinned = %v, want %v", pinned, !pinned)
+			}
+		})
+	}
+}
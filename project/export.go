@@ -0,0 +1,163 @@
+package project
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ExportFormats maps the supported export formats to their content type.
+var ExportFormats = map[string]string{
+	"csv":      "text/csv; charset=utf-8",
+	"json":     "application/json; charset=utf-8",
+	"markdown": "text/markdown; charset=utf-8",
+}
+
+// ExportedStory is a story as it appears in an export.
+type ExportedStory struct {
+	ID     string   `json:"id"`
+	Title  string   `json:"title"`
+	Size   Size     `json:"size"`
+	Points *float64 `json:"points"`
+	Author string   `json:"author"`
+	URL    string   `json:"url"`
+}
+
+// ExportedGroup is a group of stories as it appears in a grouped export.
+type ExportedGroup struct {
+	Name    string          `json:"name"`
+	Stories []ExportedStory `json:"stories"`
+}
+
+// Link returns the URL of the story id.
+func (b Backlog) Link(id string) string {
+	return b.BaseURL + id
+}
+
+// Export writes the backlog to w in format, grouping the stories by size when grouped is true.
+func Export(w io.Writer, backlog Backlog, format string, grouped bool) error {
+	groups := exportGroups(backlog, grouped)
+
+	switch format {
+	case "csv":
+		return exportCSV(w, groups, grouped)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if grouped {
+			return enc.Encode(groups)
+		}
+		return enc.Encode(groups[0].Stories)
+	case "markdown":
+		return exportMarkdown(w, groups, grouped)
+	}
+
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+func exportGroups(backlog Backlog, grouped bool) []ExportedGroup {
+	convert := func(ss []Story) []ExportedStory {
+		es := []ExportedStory{}
+		for _, s := range ss {
+			e := ExportedStory{
+				ID:     s.ID,
+				Title:  s.Title,
+				Size:   s.Size,
+				Author: s.Author,
+				URL:    backlog.Link(s.ID),
+			}
+			p, ok := backlog.Scale.Points(s.Size)
+			if ok {
+				e.Points = &p
+			}
+			es = append(es, e)
+		}
+		return es
+	}
+
+	if !grouped {
+		return []ExportedGroup{{Stories: convert(backlog.Stories)}}
+	}
+
+	var groups []ExportedGroup
+	for _, g := range backlog.BySize() {
+		groups = append(groups, ExportedGroup{Name: g.Name, Stories: convert(g.Stories)})
+	}
+	return groups
+}
+
+func exportCSV(w io.Writer, groups []ExportedGroup, grouped bool) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"Key", "Title", "Size", "Points", "Author", "URL"}
+	if grouped {
+		header = append([]string{"Group"}, header...)
+	}
+	err := cw.Write(header)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range groups {
+		for _, s := range g.Stories {
+			row := []string{s.ID, csvCell(s.Title), csvCell(string(s.Size)), points(s.Points), csvCell(s.Author), s.URL}
+			if grouped {
+				row = append([]string{csvCell(g.Name)}, row...)
+			}
+			err = cw.Write(row)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func exportMarkdown(w io.Writer, groups []ExportedGroup, grouped bool) error {
+	for i, g := range groups {
+		if grouped {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "## %s\n\n", markdownCell(g.Name))
+		}
+
+		fmt.Fprintln(w, "| Key | Title | Size | Points | Author |")
+		fmt.Fprintln(w, "|-----|-------|------|-------:|--------|")
+		for _, s := range g.Stories {
+			_, err := fmt.Fprintf(w, "| [%s](%s) | %s | %s | %s | %s |\n",
+				s.ID, s.URL, markdownCell(s.Title), markdownCell(string(s.Size)), points(s.Points), markdownCell(s.Author))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// csvCell prefixes s with a quote when it starts with a character spreadsheets read as the
+// start of a formula so that Jira text can't run as one when the export is opened.
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+var markdownReplacer = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ")
+
+func markdownCell(s string) string {
+	return markdownReplacer.Replace(s)
+}
+
+func points(p *float64) string {
+	if p == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*p, 'f', -1, 64)
+}
@@ -0,0 +1,135 @@
+package project_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie/project"
+)
+
+var exportBacklog = project.Backlog{
+	Project: "ABC",
+	BaseURL: "https://jira.example.com/browse/",
+	Stories: []project.Story{
+		{ID: "ABC-1", Title: "Add login | logout", Size: project.Small, Author: "Nathan Fisher"},
+		{ID: "ABC-2", Title: "Create service skeleton", Size: project.Unsized},
+	},
+}
+
+func Test_Export_csv(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := project.Export(&buf, exportBacklog, "csv", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("got len(rows) = %v, want 3", len(rows))
+	}
+
+	expected := []string{"ABC-1", "Add login | logout", "S", "2", "Nathan Fisher", "https://jira.example.com/browse/ABC-1"}
+	for i, v := range expected {
+		if rows[1][i] != v {
+			t.Errorf("got row[1][%v] = %v, want %v", i, rows[1][i], v)
+		}
+	}
+
+	if rows[2][3] != "" {
+		t.Errorf("got points = %v for unsized story, want empty", rows[2][3])
+	}
+}
+
+func Test_Export_csv_formula(t *testing.T) {
+	t.Parallel()
+
+	backlog := project.Backlog{
+		Project: "ABC",
+		Stories: []project.Story{
+			{ID: "ABC-1", Title: `=HYPERLINK("https://evil.example.com","x")`, Author: "@admin", Size: project.Small},
+			{ID: "ABC-2", Title: "+1 for dark mode", Author: "-", Size: project.Small},
+			{ID: "ABC-3", Title: "Add 1+1 calculator", Author: "Nathan Fisher", Size: project.Small},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := project.Export(&buf, backlog, "csv", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	td := []struct {
+		row, col int
+		want     string
+	}{
+		{1, 2, `'=HYPERLINK("https://evil.example.com","x")`},
+		{1, 5, "'@admin"},
+		{2, 2, "'+1 for dark mode"},
+		{2, 5, "'-"},
+		{3, 2, "Add 1+1 calculator"},
+		{3, 5, "Nathan Fisher"},
+	}
+	for _, tc := range td {
+		if rows[tc.row][tc.col] != tc.want {
+			t.Errorf("got row[%v][%v] = %v, want %v", tc.row, tc.col, rows[tc.row][tc.col], tc.want)
+		}
+	}
+}
+
+func Test_Export_json_grouped(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := project.Export(&buf, exportBacklog, "json", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var groups []project.ExportedGroup
+	err = json.Unmarshal(buf.Bytes(), &groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(groups) != 7 {
+		t.Fatalf("got len(groups) = %v, want 7", len(groups))
+	}
+
+	if len(groups[2].Stories) != 1 || *groups[2].Stories[0].Points != 2.0 {
+		t.Errorf("got %+v, want S group with 2 point story", groups[2])
+	}
+}
+
+func Test_Export_markdown(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := project.Export(&buf, exportBacklog, "markdown", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md := buf.String()
+	if !strings.Contains(md, `| [ABC-1](https://jira.example.com/browse/ABC-1) | Add login \| logout | S | 2 | Nathan Fisher |`) {
+		t.Errorf("got markdown without escaped story row:\n%s", md)
+	}
+
+	err = project.Export(&buf, exportBacklog, "xml", false)
+	if err == nil {
+		t.Error("got nil error for unknown format, want error")
+	}
+}
@@ -1,17 +1,28 @@
 package project
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/share"
 )
 
 func FlowHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
-		tmpl := LoadTemplates(true)
+		tmpl := Templates(req, true)
 		projectID := req.URL.Query().Get("project")
 
-		err := tmpl.ExecuteTemplate(w, "story_flow_head", nil)
+		err := ExecuteTemplate(req.Context(), tmpl, w, "story_flow_head", nil)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -29,7 +40,7 @@ func FlowHandler() http.HandlerFunc {
 			Project: projectID,
 		}
 
-		err = tmpl.ExecuteTemplate(w, "story_flow_content", &contents)
+		err = ExecuteTemplate(req.Context(), tmpl, w, "story_flow_content", &contents)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -38,40 +49,320 @@ func FlowHandler() http.HandlerFunc {
 }
 
 // TshirtHandler handles estimation for individual stories with examples for each tee-shirt size where available.
-func TshirtHandler(fn func(wallie.Config, []*http.Cookie) Client, config wallie.Config) http.HandlerFunc {
+func TshirtHandler(fn func(wallie.Config, []*http.Cookie) Client, config wallie.Config, history History, exemplars Exemplars) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
-		tmpl := LoadTemplates(config.AlwaysReloadHTML)
-		client := fn(config, req.Cookies())
+		tmpl := Templates(req, config.AlwaysReloadHTML)
+		client := clientFor(fn, config, req)
 		projectID := req.URL.Query().Get("project")
+		if !validProject(w, req, projectID) {
+			return
+		}
 
-		err := tmpl.ExecuteTemplate(w, "story_estimation_head", nil)
-		if err != nil {
+		isJSON := AcceptsJSON(req)
+		fail := func(err error) {
+			if isJSON {
+				WriteError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
 			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+
+		perms, err := PermissionsFor(client, config, projectID)
+		if err != nil && req.Method == http.MethodPost {
+			fail(err)
+			return
+		}
+		if err != nil {
+			// the backlog can still be shown read-only when the permissions are unavailable.
+			log.Printf("unable to read permissions for %v: %v\n", projectID, err)
+		}
+		if req.Method == http.MethodPost && !perms.Estimate {
+			forbid(w, isJSON)
 			return
 		}
 
-		flusher, ok := w.(http.Flusher)
-		if ok {
-			flusher.Flush()
+		if !isJSON {
+			err := ExecuteTemplate(req.Context(), tmpl, w, "story_estimation_head", nil)
+			if err != nil {
+				fail(err)
+				return
+			}
+
+			flusher, ok := w.(http.Flusher)
+			if ok {
+				flusher.Flush()
+			}
 		}
 
 		if req.Method == http.MethodPost {
 			err := req.ParseForm()
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+				fail(err)
 				return
 			}
 
-			id := req.FormValue("id")
 			title := req.FormValue("title")
 			description := req.FormValue("description")
-			size := req.FormValue("size")
+			patch := StoryPatch{Title: &title, Description: &description}
+			if size := Size(req.FormValue("size")); size != "" {
+				patch.Size = &size
+			}
+
+			_, err = Resize(client, history, ScaleFor(config, projectID), projectID, req.FormValue("id"), patch, sessionID(req, config.SessionName), req.FormValue("room"))
+			if errors.Is(err, ErrUnknownSize) {
+				if isJSON {
+					WriteError(w, http.StatusBadRequest, err.Error())
+				} else {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+				}
+				return
+			}
+			if err != nil {
+				fail(err)
+				return
+			}
+		}
+
+		backlog, err := client.ListStories(projectID)
+		if err != nil {
+			fail(err)
+			return
+		}
+		backlog.CanEstimate = perms.Estimate
+
+		backlog.Exemplars, err = exemplars.List(projectID)
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		if isJSON {
+			WriteJSON(w, http.StatusOK, &backlog)
+			return
+		}
+
+		err = ExecuteTemplate(req.Context(), tmpl, w, "story_estimation_content", &backlog)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// HistoryHandler renders the estimation changes of a project or of a single story when id is provided.
+func HistoryHandler(history History, config wallie.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		tmpl := Templates(req, config.AlwaysReloadHTML)
+		projectID := req.URL.Query().Get("project")
+		id := req.URL.Query().Get("id")
+
+		if id != "" {
+			changes, err := history.Story(projectID, id)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			err = ExecuteTemplate(req.Context(), tmpl, w, "story_history", changes)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		changes, err := history.Project(projectID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		render(w, req, tmpl, "project_history", &HistoryPage{Project: projectID, Changes: changes})
+	}
+}
+
+// AccuracyHandler reports the cycle time distribution of completed stories for each size.
+func AccuracyHandler(fn func(wallie.Config, []*http.Cookie) Client, config wallie.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		tmpl := Templates(req, config.AlwaysReloadHTML)
+		client := clientFor(fn, config, req)
+		projectID := req.URL.Query().Get("project")
+		if !validProject(w, req, projectID) {
+			return
+		}
+
+		days, err := strconv.Atoi(req.URL.Query().Get("days"))
+		if err != nil || days < 1 {
+			days = 90
+		}
+		since := time.Now().UTC().AddDate(0, 0, -days)
+
+		completed, err := client.ListCompleted(projectID, since)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		report := NewAccuracyReport(projectID, since, ScaleFor(config, projectID), completed)
+		render(w, req, tmpl, "accuracy_report", &report)
+	}
+}
+
+// SimilarHandler renders the sized stories most similar to the title and description provided.
+func SimilarHandler(similarity Similarity, config wallie.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		tmpl := Templates(req, config.AlwaysReloadHTML)
+		q := req.URL.Query()
+		projectID := q.Get("project")
+		story := Story{
+			ID:          q.Get("id"),
+			Title:       q.Get("title"),
+			Description: q.Get("description"),
+		}
+
+		neighbours, err := similarity.Similar(projectID, story, 5)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var contents = struct {
+			Project    string
+			Neighbours []Neighbour
+		}{
+			Project:    projectID,
+			Neighbours: neighbours,
+		}
+
+		err = ExecuteTemplate(req.Context(), tmpl, w, "story_similar", &contents)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// IndexHandler rebuilds the similarity index of a project from the stories completed in the last year.
+func IndexHandler(fn func(wallie.Config, []*http.Cookie) Client, config wallie.Config, similarity Similarity) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		client := clientFor(fn, config, req)
+		projectID := req.URL.Query().Get("project")
+		if !validProject(w, req, projectID) {
+			return
+		}
+
+		if !canFacilitate(w, req, client, config, projectID) {
+			return
+		}
+
+		completed, err := client.ListCompleted(projectID, time.Now().UTC().AddDate(-1, 0, 0))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		err = similarity.Index(projectID, completed)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		http.Redirect(w, req, "/tshirt?project="+url.QueryEscape(projectID), http.StatusSeeOther)
+	}
+}
+
+// ExemplarHandler lists the pinned exemplars of a project and pins or unpins stories on POST.
+func ExemplarHandler(fn func(wallie.Config, []*http.Cookie) Client, config wallie.Config, exemplars Exemplars) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		tmpl := Templates(req, config.AlwaysReloadHTML)
+		projectID := req.URL.Query().Get("project")
+
+		if req.Method == http.MethodPost {
+			client := clientFor(fn, config, req)
+			if !canFacilitate(w, req, client, config, projectID) {
+				return
+			}
+
+			err := req.ParseForm()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
 
-			err = client.UpdateStory(projectID, id, title, description, size)
+			id := req.FormValue("id")
+			if req.FormValue("action") == "unpin" {
+				err = exemplars.Unpin(projectID, id)
+			} else {
+				err = pinExemplar(client, exemplars, projectID, id)
+			}
+			if errors.Is(err, ErrNotExemplar) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
+
+			http.Redirect(w, req, "/exemplars?project="+url.QueryEscape(projectID), http.StatusSeeOther)
+			return
+		}
+
+		ss, err := exemplars.List(projectID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		page := ExemplarPage{
+			Project:   projectID,
+			Exemplars: ss,
+			Scale:     ScaleFor(config, projectID),
+		}
+		render(w, req, tmpl, "exemplar_page", &page)
+	}
+}
+
+func pinExemplar(client Client, exemplars Exemplars, projectID, id string) error {
+	story, err := client.GetStory(projectID, id)
+	if err != nil {
+		return err
+	}
+
+	if story.Size == Unsized {
+		return fmt.Errorf("story %v has not been sized: %w", id, ErrNotExemplar)
+	}
+
+	if !story.IsDone() {
+		return fmt.Errorf("story %v has not been completed: %w", id, ErrNotExemplar)
+	}
+
+	return exemplars.Pin(projectID, story)
+}
+
+// ExportHandler downloads the backlog as CSV, JSON or Markdown optionally grouped by size.
+func ExportHandler(fn func(wallie.Config, []*http.Cookie) Client, config wallie.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		client := clientFor(fn, config, req)
+		projectID := req.URL.Query().Get("project")
+		if !validProject(w, req, projectID) {
+			return
+		}
+
+		grouped := req.URL.Query().Get("group") == "size"
+
+		format := req.URL.Query().Get("format")
+		if format == "" {
+			format = "csv"
+		}
+		contentType, ok := ExportFormats[format]
+		if !ok {
+			http.Error(w, "unknown export format", http.StatusBadRequest)
+			return
 		}
 
 		backlog, err := client.ListStories(projectID)
@@ -80,10 +371,228 @@ func TshirtHandler(fn func(wallie.Config, []*http.Cookie) Client, config wallie.
 			return
 		}
 
-		err = tmpl.ExecuteTemplate(w, "story_estimation_content", &backlog)
+		ext := format
+		if format == "markdown" {
+			ext = "md"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-backlog.%s"`, url.PathEscape(projectID), ext))
+
+		err = Export(w, backlog, format, grouped)
+		if err != nil {
+			log.Printf("unable to export %v backlog: %v\n", projectID, err)
+		}
+	}
+}
+
+// PrintHandler renders the stories of a project, optionally limited to a size group, as a
+// PDF of index cards.
+func PrintHandler(fn func(wallie.Config, []*http.Cookie) Client, config wallie.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		client := clientFor(fn, config, req)
+		projectID := req.URL.Query().Get("project")
+		if !validProject(w, req, projectID) {
+			return
+		}
+
+		paper := req.URL.Query().Get("paper")
+		if paper == "" {
+			paper = "a4"
+		}
+		size, ok := PaperSizes[strings.ToLower(paper)]
+		if !ok {
+			http.Error(w, "unknown paper size", http.StatusBadRequest)
+			return
+		}
+
+		backlog, err := client.ListStories(projectID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		stories, err := PrintStories(backlog, req.URL.Query().Get("size"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s-cards.pdf"`, url.PathEscape(projectID)))
+
+		err = Print(w, backlog, stories, size)
+		if err != nil {
+			log.Printf("unable to print %v cards: %v\n", projectID, err)
+		}
+	}
+}
+
+// KanbanHandler renders the stories of a project grouped by their workflow status.
+func KanbanHandler(fn func(wallie.Config, []*http.Cookie) Client, config wallie.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		tmpl := Templates(req, config.AlwaysReloadHTML)
+		client := clientFor(fn, config, req)
+		projectID := req.URL.Query().Get("project")
+		if !validProject(w, req, projectID) {
+			return
+		}
+
+		backlog, err := client.ListStories(projectID)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+
+		render(w, req, tmpl, "kanban_board", backlog)
+	}
+}
+
+// WallboardHandler renders a single wallboard slide which refreshes to the next slide once
+// the rotation interval has passed.
+func WallboardHandler(wallboard *Wallboard, config wallie.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		tmpl := Templates(req, config.AlwaysReloadHTML)
+
+		n, err := strconv.Atoi(req.URL.Query().Get("slide"))
+		if err != nil {
+			n = 0
+		}
+
+		slide, ok := wallboard.Slide(n)
+		if !ok {
+			http.Error(w, "no wallboard projects configured", http.StatusNotFound)
+			return
+		}
+		slide.Share = req.URL.Query().Get(share.Param)
+
+		render(w, req, tmpl, "wallboard", slide)
+	}
+}
+
+// maxImportSize is the largest CSV file accepted by the ImportHandler.
+const maxImportSize = 1 << 20
+
+// ImportHandler previews the stories of an uploaded CSV file and creates them in the project.
+func ImportHandler(fn func(wallie.Config, []*http.Cookie) Client, config wallie.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		tmpl := Templates(req, config.AlwaysReloadHTML)
+		projectID := req.URL.Query().Get("project")
+		if !validProject(w, req, projectID) {
+			return
+		}
+
+		page := ImportPage{Project: projectID, DryRun: true}
+
+		if req.Method == http.MethodPost {
+			req.Body = http.MaxBytesReader(w, req.Body, maxImportSize)
+			data, err := importData(req)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if len(data) > maxImportSize {
+				http.Error(w, "CSV file is too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			page.CSV = data
+			page.DryRun = req.FormValue("action") != "import"
+			page.Rows, err = ParseImport(strings.NewReader(data), ScaleFor(config, projectID))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			client := clientFor(fn, config, req)
+			if !page.DryRun && !canFacilitate(w, req, client, config, projectID) {
+				return
+			}
+
+			page.Rows = Import(client, projectID, page.Rows, page.DryRun)
+		}
+
+		render(w, req, tmpl, "import_page", &page)
+	}
+}
+
+// importData returns the CSV from either the uploaded file or the csv form field.
+func importData(req *http.Request) (string, error) {
+	err := req.ParseMultipartForm(maxImportSize)
+	if err != nil && err != http.ErrNotMultipart {
+		return "", err
+	}
+
+	f, _, err := req.FormFile("file")
+	if err == http.ErrMissingFile || err == http.ErrNotMultipart {
+		return req.FormValue("csv"), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// forbid rejects a change the user does not have the permission for.
+func forbid(w http.ResponseWriter, isJSON bool) {
+	if isJSON {
+		WriteError(w, http.StatusForbidden, ErrForbidden.Error())
+		return
+	}
+	http.Error(w, ErrForbidden.Error(), http.StatusForbidden)
+}
+
+// clientFor returns the client of the user making req, its requests are traced as part of req.
+func clientFor(fn func(wallie.Config, []*http.Cookie) Client, config wallie.Config, req *http.Request) Client {
+	return withContext(fn(config, req.Cookies()), req.Context())
+}
+
+// validProject returns true when projectID can be a Jira project key, otherwise the
+// request is rejected as not found so the ID never reaches a Jira query.
+func validProject(w http.ResponseWriter, req *http.Request, projectID string) bool {
+	if validAPIProject.MatchString(projectID) {
+		return true
+	}
+	if AcceptsJSON(req) {
+		WriteError(w, http.StatusNotFound, ErrInvalidProject.Error())
+	} else {
+		http.Error(w, "Invalid project ID", http.StatusNotFound)
+	}
+	return false
+}
+
+// canFacilitate returns true when the user may facilitate the project, otherwise the
+// request is rejected.
+func canFacilitate(w http.ResponseWriter, req *http.Request, client Client, config wallie.Config, projectID string) bool {
+	isJSON := AcceptsJSON(req)
+	perms, err := PermissionsFor(client, config, projectID)
+	if err != nil {
+		if isJSON {
+			WriteError(w, http.StatusInternalServerError, err.Error())
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return false
+	}
+	if !perms.Facilitate {
+		forbid(w, isJSON)
+		return false
+	}
+	return true
+}
+
+// sessionID returns an opaque identifier for the session so the cookie value isn't stored.
+func sessionID(req *http.Request, sessionName string) string {
+	c, err := req.Cookie(sessionName)
+	if err != nil {
+		return ""
 	}
+	h := sha256.Sum256([]byte(c.Value))
+	return hex.EncodeToString(h[:])[:12]
 }
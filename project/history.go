@@ -0,0 +1,105 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// History is an append-only record of estimation changes.
+type History interface {
+	Record(change Change) error
+	Project(projectID string) ([]Change, error)
+	Story(projectID, id string) ([]Change, error)
+}
+
+// Change is a single estimation change of a story.
+type Change struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Project string    `json:"project"`
+	ID      string    `json:"id"`
+	From    Size      `json:"from"`
+	To      Size      `json:"to"`
+	Session string    `json:"session,omitempty"`
+	Room    string    `json:"room,omitempty"`
+}
+
+// HistoryPage is the estimation changes of a project.
+type HistoryPage struct {
+	Project string   `json:"project"`
+	Changes []Change `json:"changes"`
+}
+
+// Count returns the number of distinct stories that have changed.
+func (h HistoryPage) Count() int {
+	m := make(map[string]bool)
+	for _, v := range h.Changes {
+		m[v.ID] = true
+	}
+	return len(m)
+}
+
+// ErrUnknownSize is returned when a story is resized to a size missing from the scale.
+var ErrUnknownSize = errors.New("unknown size")
+
+// Resize applies patch to story id and records the change in history when the size differs
+// from the current one. Resizing to Unsized removes the estimate. The story has already
+// been updated when recording fails so the failure is logged rather than returned.
+func Resize(client Client, history History, scale Scale, projectID, id string, patch StoryPatch, session, room string) (Story, error) {
+	var size string
+	if patch.Size != nil {
+		size = string(*patch.Size)
+		_, ok := scale.Points(*patch.Size)
+		if !ok && *patch.Size != Unsized {
+			return Story{}, fmt.Errorf("%w %v for scale %v", ErrUnknownSize, size, scale.Name)
+		}
+	}
+
+	story, err := client.GetStory(projectID, id)
+	if err != nil {
+		return story, err
+	}
+
+	if patch.Title != nil {
+		story.Title = *patch.Title
+	}
+	if patch.Description != nil {
+		story.Description = *patch.Description
+	}
+
+	err = client.UpdateStory(projectID, id, story.Title, story.Description, size)
+	if err != nil {
+		return story, err
+	}
+
+	previous := story.Size
+	if patch.Size == nil || previous == *patch.Size {
+		return story, nil
+	}
+	story.Size = *patch.Size
+
+	err = recordChange(client, history, Change{
+		Time:    time.Now().UTC(),
+		Project: projectID,
+		ID:      id,
+		From:    previous,
+		To:      story.Size,
+		Session: session,
+		Room:    room,
+	})
+	if err != nil {
+		log.Printf("unable to record estimation of %v: %v\n", id, err)
+	}
+	return story, nil
+}
+
+func recordChange(client Client, history History, change Change) error {
+	user, err := client.User()
+	if err != nil {
+		return err
+	}
+	change.User = user
+	return history.Record(change)
+}
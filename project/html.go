@@ -1,8 +1,18 @@
 package project
 
 import (
+	"context"
 	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
 	"sync"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/csrf"
+	"github.com/nfisher/wallie/secure"
+	"github.com/nfisher/wallie/trace"
 )
 
 type templates struct {
@@ -13,7 +23,8 @@ type templates struct {
 
 var tpl templates
 
-// LoadTemplates loads the html templates associated with this package.
+// LoadTemplates loads the html templates associated with this package. A clone is returned
+// so the parsed templates are never executed and can be cloned again for each request.
 func LoadTemplates(alwaysReload bool) *template.Template {
 	tpl.RLock()
 	isLoaded := tpl.isLoaded
@@ -21,19 +32,64 @@ func LoadTemplates(alwaysReload bool) *template.Template {
 	tpl.RUnlock()
 
 	if !alwaysReload && isLoaded {
-		return t
+		return template.Must(t.Clone())
 	}
 
 	tpl.Lock()
 	defer tpl.Unlock()
-	if !alwaysReload && isLoaded {
-		t := tpl.templates
-		return t
+	if !alwaysReload && tpl.isLoaded {
+		return template.Must(tpl.templates.Clone())
 	}
 
-	tpl.templates = template.Must(template.ParseFiles("tpl/project.html"))
+	tpl.templates = template.Must(parseTemplates())
 	tpl.isLoaded = true
-	t = tpl.templates
 
-	return t
+	return template.Must(tpl.templates.Clone())
+}
+
+func parseTemplates() (*template.Template, error) {
+	return template.New("project.html").
+		Funcs(csrf.Funcs(nil)).
+		Funcs(secure.Funcs(nil)).
+		Funcs(wallie.TemplateFuncs()).
+		ParseFS(wallie.Assets(), "tpl/project.html")
+}
+
+// CheckTemplates parses the html templates and returns any error rather than panicking.
+func CheckTemplates() error {
+	_, err := parseTemplates()
+	return err
+}
+
+// Templates loads the html templates with the template functions bound to req.
+func Templates(req *http.Request, alwaysReload bool) *template.Template {
+	return LoadTemplates(alwaysReload).Funcs(csrf.Funcs(req)).Funcs(secure.Funcs(req))
+}
+
+// ExecuteTemplate executes the named template in its own span of the trace in ctx.
+func ExecuteTemplate(ctx context.Context, tmpl *template.Template, w io.Writer, name string, v interface{}) error {
+	_, span := trace.Start(ctx, "template "+name, trace.Internal)
+	err := tmpl.ExecuteTemplate(w, name, v)
+	span.Fail(err)
+	span.Finish()
+	return err
+}
+
+// StaticPrefix is the path the static assets are served from.
+const StaticPrefix = "/static/"
+
+// StaticHandler serves the static assets, vendored assets are cached for a year as their
+// paths include their version.
+func StaticHandler() http.Handler {
+	return http.StripPrefix(StaticPrefix, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		static, err := fs.Sub(wallie.Assets(), "static")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if strings.HasPrefix(req.URL.Path, "vendor/") {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		}
+		http.FileServer(http.FS(static)).ServeHTTP(w, req)
+	}))
 }
@@ -3,18 +3,16 @@ package project_test
 import (
 	"bytes"
 	"fmt"
-	"os"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/secure"
 )
 
-func init() {
-	// tests require dir to be changed
-	os.Chdir("..")
-}
-
 func Test_render_dialogue(t *testing.T) {
 	t.Parallel()
 
@@ -149,3 +147,85 @@ func containsAttr(component, key, value string) bool {
 	attr := fmt.Sprintf(`%s="%s"`, key, value)
 	return strings.Contains(component, attr)
 }
+
+func Test_render_history(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	tpl := project.LoadTemplates(false)
+	changes := []project.Change{
+		{User: "nfisher", ID: "ABC-1", From: project.Unsized, To: project.Small},
+		{User: "nfisher", ID: "ABC-1", From: project.Small, To: project.Large},
+	}
+	err := tpl.ExecuteTemplate(&buf, "story_history", changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := strings.Count(buf.String(), "<td>nfisher</td>")
+	if actual != 2 {
+		t.Errorf("got count(user) = %v, want 2", actual)
+	}
+}
+
+func Test_render_accuracy(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	tpl := project.LoadTemplates(false)
+	report := project.NewAccuracyReport("Wallie", time.Now(), project.TShirt, []project.Completed{
+		{Story: project.Story{ID: "ABC-1", Size: project.Small}, CycleTime: 48 * time.Hour},
+	})
+	err := tpl.ExecuteTemplate(&buf, "accuracy_report", &report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := strings.Count(buf.String(), "<svg")
+	if actual != 1 {
+		t.Errorf("got count(svg) = %v, want 1", actual)
+	}
+}
+
+func Test_render_exemplars(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	tpl := project.LoadTemplates(false)
+	backlog := project.Backlog{
+		Project: "Wallie",
+		Exemplars: []project.Story{
+			{ID: "ABC-1", Title: "Add login page", Size: project.Small},
+			{ID: "ABC-2", Title: "Create service skeleton", Size: project.Large},
+		},
+	}
+	err := tpl.ExecuteTemplate(&buf, "story_estimate_backlog", &backlog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := strings.Count(buf.String(), `class="exemplars"`)
+	if actual != 2 {
+		t.Errorf("got count(.exemplars) = %v, want 2", actual)
+	}
+}
+
+func Test_render_script_nonce(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	var nonce string
+	h := (&secure.Headers{}).Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		nonce = secure.Nonce(req)
+		err := project.Templates(req, false).ExecuteTemplate(&buf, "story_script", &project.Backlog{Project: "Wallie"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/estimation", nil))
+
+	want := `<script nonce="` + nonce + `">`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("got script without %q, want the request nonce", want)
+	}
+}
@@ -0,0 +1,140 @@
+package project
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ImportRow is a single story read from a CSV import and the outcome of creating it.
+type ImportRow struct {
+	Line  int    `json:"line"`
+	Story Story  `json:"story"`
+	Error string `json:"error,omitempty"`
+	// Created is true once the story has been created in Jira.
+	Created bool `json:"created"`
+}
+
+// OK returns true when the row is valid and, if it has been imported, was created successfully.
+func (r ImportRow) OK() bool {
+	return r.Error == ""
+}
+
+// ImportPage is the preview or the result of a CSV import.
+type ImportPage struct {
+	Project string
+	CSV     string
+	DryRun  bool
+	Rows    []ImportRow
+}
+
+// Count returns the number of rows in the import.
+func (p ImportPage) Count() int {
+	return len(p.Rows)
+}
+
+// Valid returns the number of rows without errors.
+func (p ImportPage) Valid() int {
+	var n int
+	for _, r := range p.Rows {
+		if r.OK() {
+			n++
+		}
+	}
+	return n
+}
+
+// ParseImport reads stories from CSV with a header row naming the Title, Description and
+// Size columns in any order. Title is required and sizes must be part of the scale, rows
+// which fail validation are returned with an error rather than failing the import.
+func ParseImport(r io.Reader, scale Scale) ([]ImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("import is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, v := range header {
+		columns[strings.ToLower(strings.TrimSpace(v))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("import header must include a Title column")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return rows, err
+		}
+
+		// quoted fields may span lines so the line is taken from the reader.
+		line, _ := cr.FieldPos(0)
+		row := ImportRow{
+			Line: line,
+			Story: Story{
+				Title:       field(record, "title"),
+				Description: field(record, "description"),
+				Size:        Size(field(record, "size")),
+			},
+		}
+
+		switch _, ok := scale.Points(row.Story.Size); {
+		case row.Story.Title == "":
+			row.Error = "title is required"
+		case row.Story.Size != "" && !ok:
+			row.Error = fmt.Sprintf("unknown size %v", row.Story.Size)
+		}
+		if row.Story.Size == "" {
+			row.Story.Size = Unsized
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// Import creates the valid rows in projectID and records the outcome of each row. Nothing
+// is created when dryRun is true.
+func Import(client Client, projectID string, rows []ImportRow, dryRun bool) []ImportRow {
+	for i, r := range rows {
+		if !r.OK() || dryRun {
+			continue
+		}
+
+		size := string(r.Story.Size)
+		if r.Story.Size == Unsized {
+			size = ""
+		}
+
+		story, err := client.CreateStory(projectID, r.Story.Title, r.Story.Description, size)
+		if err != nil {
+			rows[i].Error = err.Error()
+			continue
+		}
+		rows[i].Story = story
+		rows[i].Created = true
+	}
+
+	return rows
+}
@@ -0,0 +1,134 @@
+package project_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/project"
+)
+
+const importCSV = `Size,Title,Description
+S,Add login page,"Login form, with ""quotes"""
+,Create service skeleton,
+XXXL,Too big,
+M,,missing title
+`
+
+func Test_ParseImport(t *testing.T) {
+	t.Parallel()
+
+	rows, err := project.ParseImport(strings.NewReader(importCSV), project.TShirt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 4 {
+		t.Fatalf("got len(rows) = %v, want 4", len(rows))
+	}
+
+	td := []struct {
+		line int
+		ok   bool
+		size project.Size
+	}{
+		{2, true, project.Small},
+		{3, true, project.Unsized},
+		{4, false, "XXXL"},
+		{5, false, project.Medium},
+	}
+
+	for i, tc := range td {
+		if rows[i].Line != tc.line || rows[i].OK() != tc.ok || rows[i].Story.Size != tc.size {
+			t.Errorf("got row %+v, want line %v ok %v size %v", rows[i], tc.line, tc.ok, tc.size)
+		}
+	}
+
+	if rows[0].Story.Description != `Login form, with "quotes"` {
+		t.Errorf("got description = %v, want quoted CSV field", rows[0].Story.Description)
+	}
+
+	_, err = project.ParseImport(strings.NewReader("Summary\nfoo\n"), project.TShirt)
+	if err == nil {
+		t.Error("got nil error for missing Title column, want error")
+	}
+}
+
+func Test_ParseImport_multiline(t *testing.T) {
+	t.Parallel()
+
+	csv := "Title,Description,Size\n" +
+		"Add login page,\"First line\nsecond line\nthird line\",S\n" +
+		"Too big,,XXXL\n"
+	rows, err := project.ParseImport(strings.NewReader(csv), project.TShirt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("got len(rows) = %v, want 2", len(rows))
+	}
+	if rows[0].Line != 2 || !rows[0].OK() {
+		t.Errorf("got row %+v, want valid row on line 2", rows[0])
+	}
+	if rows[1].Line != 5 || rows[1].OK() {
+		t.Errorf("got row %+v, want invalid row on line 5", rows[1])
+	}
+}
+
+func Test_Import(t *testing.T) {
+	t.Parallel()
+
+	_, client, _ := apiFixture()
+
+	rows, err := project.ParseImport(strings.NewReader(importCSV), project.TShirt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows = project.Import(client, "ABC", rows, true)
+	if len(client.Stories) != 2 {
+		t.Errorf("got len(stories) = %v after dry-run, want 2", len(client.Stories))
+	}
+
+	rows = project.Import(client, "ABC", rows, false)
+	if len(client.Stories) != 4 {
+		t.Errorf("got len(stories) = %v, want 4", len(client.Stories))
+	}
+
+	if !rows[0].Created || rows[0].Story.ID != "ABC-3" || rows[2].Created {
+		t.Errorf("got rows %+v, want first two created", rows)
+	}
+}
+
+func Test_ImportHandler_preview(t *testing.T) {
+	t.Parallel()
+
+	_, client, _ := apiFixture()
+	fn := func(wallie.Config, []*http.Cookie) project.Client { return client }
+	h := project.ImportHandler(fn, wallie.Config{})
+
+	form := url.Values{"csv": {importCSV}, "action": {"preview"}}
+	req := httptest.NewRequest(http.MethodPost, "/import?project=ABC", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status = %v, want 200: %s", w.Code, w.Body)
+	}
+
+	body := w.Body.String()
+	if strings.Count(body, `class="card"`) != 4 {
+		t.Errorf("got count(.card) = %v, want 4", strings.Count(body, `class="card"`))
+	}
+	if !strings.Contains(body, "2 of 4 rows are valid") {
+		t.Error("got preview without validation summary")
+	}
+	if len(client.Stories) != 2 {
+		t.Errorf("got len(stories) = %v after preview, want 2", len(client.Stories))
+	}
+}
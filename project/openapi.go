@@ -0,0 +1,189 @@
+package project
+
+import "net/http"
+
+// OpenAPIPath is the path the OpenAPI description of the API is served from.
+const OpenAPIPath = APIPrefix + "openapi.json"
+
+// OpenAPIHandler serves the OpenAPI 3 description of the JSON API.
+func OpenAPIHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write([]byte(openAPI))
+}
+
+const openAPI = `{
+  "openapi": "3.0.2",
+  "info": {
+    "title": "wallie",
+    "description": "Backlog estimation for Jira projects.",
+    "version": "1"
+  },
+  "servers": [{ "url": "/api/v1" }],
+  "security": [{ "session": [] }],
+  "paths": {
+    "/projects/{project}/stories": {
+      "parameters": [{ "$ref": "#/components/parameters/project" }],
+      "get": {
+        "operationId": "listStories",
+        "summary": "Lists the stories of a project that are not done.",
+        "responses": {
+          "200": {
+            "description": "The project backlog.",
+            "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Backlog" } } }
+          },
+          "default": { "$ref": "#/components/responses/Error" }
+        }
+      }
+    },
+    "/projects/{project}/groups": {
+      "parameters": [{ "$ref": "#/components/parameters/project" }],
+      "get": {
+        "operationId": "listGroups",
+        "summary": "Lists the stories of a project grouped by size.",
+        "responses": {
+          "200": {
+            "description": "The project backlog grouped by size, unsized stories first.",
+            "content": {
+              "application/json": {
+                "schema": { "type": "array", "items": { "$ref": "#/components/schemas/Group" } }
+              }
+            }
+          },
+          "default": { "$ref": "#/components/responses/Error" }
+        }
+      }
+    },
+    "/projects/{project}/stories/{id}": {
+      "parameters": [
+        { "$ref": "#/components/parameters/project" },
+        { "name": "id", "in": "path", "required": true, "schema": { "type": "string", "pattern": "^[A-Za-z][A-Za-z0-9]*-[0-9]+$" } }
+      ],
+      "get": {
+        "operationId": "getStory",
+        "summary": "Retrieves a single story.",
+        "responses": {
+          "200": {
+            "description": "The story.",
+            "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Story" } } }
+          },
+          "default": { "$ref": "#/components/responses/Error" }
+        }
+      },
+      "patch": {
+        "operationId": "patchStory",
+        "summary": "Updates the title, description or size of a story.",
+        "parameters": [
+          {
+            "name": "X-CSRF-Token",
+            "in": "header",
+            "required": true,
+            "description": "The token from the X-CSRF-Token header of an earlier response, sent with the wallieCSRF cookie of that response.",
+            "schema": { "type": "string" }
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": { "application/json": { "schema": { "$ref": "#/components/schemas/StoryPatch" } } }
+        },
+        "responses": {
+          "200": {
+            "description": "The updated story.",
+            "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Story" } } }
+          },
+          "403": {
+            "description": "The user may not estimate stories in the project.",
+            "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Error" } } }
+          },
+          "default": { "$ref": "#/components/responses/Error" }
+        }
+      }
+    }
+  },
+  "components": {
+    "securitySchemes": {
+      "session": { "type": "apiKey", "in": "cookie", "name": "JSESSIONID" }
+    },
+    "parameters": {
+      "project": { "name": "project", "in": "path", "required": true, "schema": { "type": "string", "pattern": "^\\w+$" } }
+    },
+    "responses": {
+      "Error": {
+        "description": "The request failed.",
+        "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Error" } } }
+      }
+    },
+    "schemas": {
+      "Size": {
+        "type": "string",
+        "description": "A size from the project's estimation scale, for example XS, S, M, L, XL or XXL.",
+        "example": "M"
+      },
+      "Story": {
+        "type": "object",
+        "properties": {
+          "id": { "type": "string", "example": "ABC-123" },
+          "title": { "type": "string" },
+          "description": { "type": "string" },
+          "author": { "type": "string" },
+          "size": { "$ref": "#/components/schemas/Size" },
+          "status": { "type": "string", "example": "In Progress" }
+        }
+      },
+      "StoryPatch": {
+        "type": "object",
+        "description": "Fields which are absent are left unchanged.",
+        "properties": {
+          "title": { "type": "string" },
+          "description": { "type": "string" },
+          "size": { "$ref": "#/components/schemas/Size" }
+        }
+      },
+      "Level": {
+        "type": "object",
+        "properties": {
+          "size": { "$ref": "#/components/schemas/Size" },
+          "points": { "type": "number" },
+          "legacy": { "type": "array", "items": { "type": "number" } }
+        }
+      },
+      "Scale": {
+        "type": "object",
+        "properties": {
+          "name": { "type": "string" },
+          "levels": { "type": "array", "items": { "$ref": "#/components/schemas/Level" } }
+        }
+      },
+      "Backlog": {
+        "type": "object",
+        "properties": {
+          "project": { "type": "string" },
+          "baseURL": { "type": "string", "format": "uri" },
+          "scale": { "$ref": "#/components/schemas/Scale" },
+          "stories": { "type": "array", "items": { "$ref": "#/components/schemas/Story" } },
+          "canEstimate": { "type": "boolean", "description": "True when the user may resize and edit the stories." }
+        }
+      },
+      "Group": {
+        "type": "object",
+        "properties": {
+          "name": { "type": "string" },
+          "stories": { "type": "array", "items": { "$ref": "#/components/schemas/Story" } },
+          "exemplars": { "type": "array", "items": { "$ref": "#/components/schemas/Story" } }
+        }
+      },
+      "Error": {
+        "type": "object",
+        "properties": {
+          "error": {
+            "type": "object",
+            "properties": {
+              "status": { "type": "integer" },
+              "message": { "type": "string" }
+            }
+          }
+        }
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,70 @@
+package project
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/nfisher/wallie"
+)
+
+// Role is a wallie role layered on top of the Jira permissions of a user.
+type Role string
+
+// Roles from the most to the least privileged.
+const (
+	Facilitator Role = "facilitator"
+	Estimator   Role = "estimator"
+	Viewer      Role = "viewer"
+)
+
+// Roles are the recognised wallie roles.
+var Roles = []Role{Facilitator, Estimator, Viewer}
+
+// ErrForbidden is returned when a user attempts a change their permissions do not allow.
+var ErrForbidden = errors.New("you do not have permission to make this change")
+
+// Permissions are the changes a user may make in a project.
+type Permissions struct {
+	// Estimate allows stories to be edited and resized.
+	Estimate bool `json:"estimate"`
+	// Facilitate allows exemplars, imports and the similar stories index to be managed.
+	Facilitate bool `json:"facilitate"`
+}
+
+// RoleFor returns the wallie role of user.
+func RoleFor(config wallie.Config, user string) Role {
+	role, ok := config.Roles[user]
+	if !ok {
+		role = config.DefaultRole
+	}
+	if role == "" {
+		return Facilitator
+	}
+	return Role(strings.ToLower(role))
+}
+
+// PermissionsFor combines the Jira edit permission of the clients user with their wallie
+// role. The user is only looked up when roles are assigned to individual users.
+func PermissionsFor(client Client, config wallie.Config, projectID string) (Permissions, error) {
+	canEdit, err := client.CanEdit(projectID)
+	if err != nil || !canEdit {
+		return Permissions{}, err
+	}
+
+	role := RoleFor(config, "")
+	if len(config.Roles) > 0 {
+		user, err := client.User()
+		if err != nil {
+			return Permissions{}, err
+		}
+		role = RoleFor(config, user)
+	}
+
+	switch role {
+	case Facilitator:
+		return Permissions{Estimate: true, Facilitate: true}, nil
+	case Estimator:
+		return Permissions{Estimate: true}, nil
+	}
+	return Permissions{}, nil
+}
@@ -0,0 +1,195 @@
+package project_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/project/projecttest"
+)
+
+// permClient is a projecttest.Client with configurable permissions.
+type permClient struct {
+	*projecttest.Client
+	canEdit bool
+	user    string
+	err     error
+}
+
+func (c *permClient) CanEdit(projectID string) (bool, error) {
+	return c.canEdit, c.err
+}
+
+func (c *permClient) User() (string, error) {
+	return c.user, nil
+}
+
+// unreachableClient is a projecttest.Client which can't read stories.
+type unreachableClient struct {
+	*projecttest.Client
+}
+
+func (c *unreachableClient) GetStory(projectID, id string) (project.Story, error) {
+	return project.Story{}, errors.New("jira unavailable")
+}
+
+type noExemplars struct{}
+
+func (noExemplars) Pin(projectID string, story project.Story) error { return nil }
+func (noExemplars) Unpin(projectID, id string) error                { return nil }
+func (noExemplars) List(projectID string) ([]project.Story, error)  { return nil, nil }
+
+func Test_PermissionsFor(t *testing.T) {
+	t.Parallel()
+	roles := map[string]string{"alice": "Facilitator", "bob": "estimator", "carol": "viewer"}
+	td := []struct {
+		name        string
+		canEdit     bool
+		user        string
+		roles       map[string]string
+		defaultRole string
+		want        project.Permissions
+	}{
+		{"no roles", true, "dave", nil, "", project.Permissions{Estimate: true, Facilitate: true}},
+		{"no jira permission", false, "alice", roles, "", project.Permissions{}},
+		{"facilitator", true, "alice", roles, "", project.Permissions{Estimate: true, Facilitate: true}},
+		{"estimator", true, "bob", roles, "", project.Permissions{Estimate: true}},
+		{"viewer", true, "carol", roles, "", project.Permissions{}},
+		{"default role", true, "dave", roles, "estimator", project.Permissions{Estimate: true}},
+		{"default role without roles", true, "dave", nil, "viewer", project.Permissions{}},
+		{"unknown role", true, "erin", map[string]string{"erin": "owner"}, "", project.Permissions{}},
+	}
+
+	for _, tc := range td {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			client := &permClient{Client: &projecttest.Client{}, canEdit: tc.canEdit, user: tc.user}
+			config := wallie.Config{Roles: tc.roles, DefaultRole: tc.defaultRole}
+
+			got, err := project.PermissionsFor(client, config, "ABC")
+			if err != nil {
+				t.Fatalf("got err %v, want nil", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func Test_TshirtHandler_viewer(t *testing.T) {
+	t.Parallel()
+	client := &permClient{Client: &projecttest.Client{Stories: []project.Story{{ID: "ABC-1", Title: "Add login page"}}}}
+	fn := func(wallie.Config, []*http.Cookie) project.Client { return client }
+	h := project.TshirtHandler(fn, wallie.Config{}, &projecttest.History{}, noExemplars{})
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/tshirt?project=ABC", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status = %v, want 200", w.Code)
+	}
+	if strings.Contains(w.Body.String(), `id="modal"`) {
+		t.Errorf("got estimation dialogue, want it hidden")
+	}
+
+	form := url.Values{"id": {"ABC-1"}, "title": {"Rewritten"}, "size": {"XL"}}
+	req := httptest.NewRequest(http.MethodPost, "/tshirt?project=ABC", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	h(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("got status = %v, want 403", w.Code)
+	}
+	if client.Stories[0].Title != "Add login page" {
+		t.Errorf("got title = %v, want story unchanged", client.Stories[0].Title)
+	}
+}
+
+func Test_TshirtHandler_permissions_unavailable(t *testing.T) {
+	t.Parallel()
+	client := &permClient{Client: &projecttest.Client{Stories: []project.Story{{ID: "ABC-1", Title: "Add login page"}}}, err: errors.New("jira unavailable")}
+	fn := func(wallie.Config, []*http.Cookie) project.Client { return client }
+	h := project.TshirtHandler(fn, wallie.Config{}, &projecttest.History{}, noExemplars{})
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/tshirt?project=ABC", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status = %v, want 200", w.Code)
+	}
+	if strings.Contains(w.Body.String(), `id="modal"`) {
+		t.Errorf("got estimation dialogue, want it hidden")
+	}
+
+	form := url.Values{"id": {"ABC-1"}, "title": {"Rewritten"}, "size": {"XL"}}
+	req := httptest.NewRequest(http.MethodPost, "/tshirt?project=ABC", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	h(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("got status = %v, want 500", w.Code)
+	}
+	if client.Stories[0].Title != "Add login page" {
+		t.Errorf("got title = %v, want story unchanged", client.Stories[0].Title)
+	}
+}
+
+func Test_TshirtHandler_story_unavailable_json(t *testing.T) {
+	t.Parallel()
+	client := &unreachableClient{Client: &projecttest.Client{}}
+	fn := func(wallie.Config, []*http.Cookie) project.Client { return client }
+	h := project.TshirtHandler(fn, wallie.Config{}, &projecttest.History{}, noExemplars{})
+
+	form := url.Values{"id": {"ABC-1"}, "size": {"XL"}}
+	req := httptest.NewRequest(http.MethodPost, "/tshirt?project=ABC", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	h(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("got status = %v, want 500", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("got Content-Type = %v, want application/json", ct)
+	}
+}
+
+func Test_ExemplarHandler_forbidden_json(t *testing.T) {
+	t.Parallel()
+	client := &permClient{Client: &projecttest.Client{}}
+	fn := func(wallie.Config, []*http.Cookie) project.Client { return client }
+	h := project.ExemplarHandler(fn, wallie.Config{}, noExemplars{})
+
+	req := httptest.NewRequest(http.MethodPost, "/exemplars?project=ABC", strings.NewReader("id=ABC-1"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	h(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("got status = %v, want 403", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("got Content-Type = %v, want application/json", ct)
+	}
+}
+
+func Test_API_patch_forbidden(t *testing.T) {
+	t.Parallel()
+	client := &permClient{Client: &projecttest.Client{Stories: []project.Story{{ID: "ABC-1", Title: "Add login page"}}}}
+	fn := func(wallie.Config, []*http.Cookie) project.Client { return client }
+	h := project.APIHandler(fn, wallie.Config{}, &projecttest.History{})
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodPatch, "/api/v1/projects/ABC/stories/ABC-1", strings.NewReader(`{"size":"S"}`)))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("got status = %v, want 403", w.Code)
+	}
+	if client.Stories[0].Size != "" {
+		t.Errorf("got size = %v, want story unchanged", client.Stories[0].Size)
+	}
+}
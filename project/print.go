@@ -0,0 +1,117 @@
+package project
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/nfisher/wallie/pdf"
+	"github.com/nfisher/wallie/qr"
+)
+
+// PaperSizes maps the supported paper names to their page size.
+var PaperSizes = map[string]pdf.Size{
+	"a4":     pdf.A4,
+	"letter": pdf.Letter,
+}
+
+const (
+	cardColumns = 2
+	cardRows    = 3
+	pageMargin  = 36
+	cardPadding = 14
+	qrWidth     = 72
+)
+
+// PrintStories returns the stories in the size group, all of the stories when group is empty.
+func PrintStories(backlog Backlog, group string) ([]Story, error) {
+	if group == "" {
+		return backlog.Stories, nil
+	}
+
+	for _, g := range backlog.BySize() {
+		if g.Name == group {
+			return g.Stories, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown size group %q", group)
+}
+
+// Print writes stories to w as a PDF of index cards sized to fit paper.
+func Print(w io.Writer, backlog Backlog, stories []Story, paper pdf.Size) error {
+	doc := pdf.New(paper)
+	cardWidth := (paper.Width - 2*pageMargin) / cardColumns
+	cardHeight := (paper.Height - 2*pageMargin) / cardRows
+	perPage := cardColumns * cardRows
+
+	for i, story := range stories {
+		if i%perPage == 0 {
+			doc.AddPage()
+		}
+		n := i % perPage
+		x := pageMargin + float64(n%cardColumns)*cardWidth
+		y := pageMargin + float64(n/cardColumns)*cardHeight
+		printCard(doc, x, y, cardWidth, cardHeight, story, backlog.Link(story.ID))
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+func printCard(doc *pdf.Document, x, y, w, h float64, story Story, link string) {
+	doc.StrokeRect(x, y, w, h, 0.5)
+	inner := w - 2*cardPadding
+
+	size := string(story.Size)
+	if story.Size == Unsized || size == "" {
+		size = "?"
+	}
+	sizeWidth := pdf.TextWidth(pdf.HelveticaBold, 28, size)
+	doc.Text(x+w-cardPadding-sizeWidth, y+cardPadding+24, pdf.HelveticaBold, 28, size)
+	doc.Text(x+cardPadding, y+cardPadding+16, pdf.HelveticaBold, 16, truncate(pdf.HelveticaBold, 16, inner-sizeWidth-8, story.ID))
+
+	const leading = 18
+	top := y + cardPadding + 56
+	maxLines := int((y + h - cardPadding - qrWidth - 8 - top + leading) / leading)
+	lines := pdf.Wrap(pdf.Helvetica, 14, inner, story.Title)
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		lines[maxLines-1] = truncate(pdf.Helvetica, 14, inner, lines[maxLines-1]+"…")
+	}
+	for i, line := range lines {
+		doc.Text(x+cardPadding, top+float64(i)*leading, pdf.Helvetica, 14, line)
+	}
+
+	doc.Text(x+cardPadding, y+h-cardPadding, pdf.Helvetica, 9, truncate(pdf.Helvetica, 9, inner-qrWidth-8, story.Author))
+
+	code, err := qr.Encode(link)
+	if err != nil {
+		// links too long for a QR code are left off of the card.
+		return
+	}
+	module := float64(qrWidth) / float64(code.Size)
+	qx := x + w - cardPadding - qrWidth
+	qy := y + h - cardPadding - qrWidth
+	// runs of dark modules are drawn as one rectangle to avoid seams between them.
+	for row := 0; row < code.Size; row++ {
+		for col := 0; col < code.Size; col++ {
+			if !code.Dark(col, row) {
+				continue
+			}
+			start := col
+			for col+1 < code.Size && code.Dark(col+1, row) {
+				col++
+			}
+			doc.Rect(qx+float64(start)*module, qy+float64(row)*module, float64(col-start+1)*module, module)
+		}
+	}
+}
+
+// truncate shortens s with an ellipsis until it is no wider than width.
+func truncate(font pdf.Font, size, width float64, s string) string {
+	r := []rune(s)
+	for len(r) > 1 && pdf.TextWidth(font, size, string(r)) > width {
+		r = append(r[:len(r)-2], '…')
+	}
+	return string(r)
+}
@@ -0,0 +1,100 @@
+package project_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/pdf"
+	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/project/projecttest"
+)
+
+func Test_PrintStories(t *testing.T) {
+	t.Parallel()
+	td := []struct {
+		name  string
+		group string
+		want  int
+	}{
+		{"all stories", "", 2},
+		{"size group", "S", 1},
+		{"unsized group", string(project.Unsized), 1},
+		{"empty group", "XL", 0},
+	}
+
+	for _, tc := range td {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			stories, err := project.PrintStories(exportBacklog, tc.group)
+			if err != nil {
+				t.Fatalf("got err %v, want nil", err)
+			}
+			if len(stories) != tc.want {
+				t.Errorf("got len(stories) = %v, want %v", len(stories), tc.want)
+			}
+		})
+	}
+
+	_, err := project.PrintStories(exportBacklog, "Huge")
+	if err == nil {
+		t.Errorf("got err nil for unknown group, want error")
+	}
+}
+
+func Test_Print_pages(t *testing.T) {
+	t.Parallel()
+	var stories []project.Story
+	for i := 0; i < 7; i++ {
+		stories = append(stories, project.Story{ID: "ABC-1", Title: "A very long title that wraps over a number of lines on the card and is truncated once it runs into the QR code in the corner"})
+	}
+
+	var buf bytes.Buffer
+	err := project.Print(&buf, exportBacklog, stories, pdf.Letter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte("/Count 2")) {
+		t.Errorf("got no /Count 2 for 7 cards, want 2 pages")
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/MediaBox [0 0 612 792]")) {
+		t.Errorf("got no letter MediaBox, want 612x792")
+	}
+}
+
+func Test_PrintHandler(t *testing.T) {
+	t.Parallel()
+	client := &projecttest.Client{Stories: exportBacklog.Stories}
+	fn := func(wallie.Config, []*http.Cookie) project.Client { return client }
+	h := project.PrintHandler(fn, wallie.Config{})
+
+	td := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{"defaults", "project=ABC", http.StatusOK},
+		{"size and paper", "project=ABC&size=S&paper=letter", http.StatusOK},
+		{"unknown paper", "project=ABC&paper=a0", http.StatusBadRequest},
+		{"unknown size", "project=ABC&size=Huge", http.StatusBadRequest},
+	}
+
+	for _, tc := range td {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			w := httptest.NewRecorder()
+			h(w, httptest.NewRequest(http.MethodGet, "/print?"+tc.query, nil))
+			if w.Code != tc.status {
+				t.Errorf("got status = %v, want %v", w.Code, tc.status)
+			}
+			if tc.status == http.StatusOK && w.Header().Get("Content-Type") != "application/pdf" {
+				t.Errorf("got Content-Type = %v, want application/pdf", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -1,20 +1,51 @@
 package project
 
+import (
+	"context"
+	"strings"
+	"time"
+)
+
 type Client interface {
 	ListStories(projectID string) (Backlog, error)
+	ListCompleted(projectID string, since time.Time) ([]Completed, error)
+	GetStory(projectID, id string) (Story, error)
+	CreateStory(projectID, title, description, size string) (Story, error)
 	UpdateStory(projectID, id, title, description, size string) error
+	User() (string, error)
+	// CanEdit returns true when the user may edit the stories of the project.
+	CanEdit(projectID string) (bool, error)
+}
+
+// contextClient is implemented by clients which can trace their requests as part of the
+// work in a context.
+type contextClient interface {
+	WithContext(ctx context.Context) Client
+}
+
+// withContext binds c to ctx when it supports it.
+func withContext(c Client, ctx context.Context) Client {
+	if cc, ok := c.(contextClient); ok {
+		return cc.WithContext(ctx)
+	}
+	return c
 }
 
 // Backlog is a projects new stories which need sizing or are not done.
 type Backlog struct {
-	Project string
-	Stories []Story
-	BaseURL string
+	Project string  `json:"project"`
+	Stories []Story `json:"stories"`
+	BaseURL string  `json:"baseURL"`
+	Scale   Scale   `json:"scale"`
+	// Exemplars are completed stories pinned as a reference for each size.
+	Exemplars []Story `json:"exemplars,omitempty"`
+	// CanEstimate is true when the viewer may resize and edit the stories.
+	CanEstimate bool `json:"canEstimate"`
 }
 
-// Sizes returns the available tee-shirt sizes.
+// Sizes returns the available sizes of the backlogs scale.
 func (b Backlog) Sizes() []Size {
-	return sizes[1:]
+	return b.Scale.Sizes()
 }
 
 // Count returns the count of stories not done.
@@ -27,7 +58,7 @@ func (b Backlog) BySize() []*Group {
 	var gg []*Group
 
 	m := make(map[Size]*Group)
-	for _, v := range sizes {
+	for _, v := range append([]Size{Unsized}, b.Sizes()...) {
 		g := &Group{
 			Name: string(v),
 		}
@@ -43,29 +74,60 @@ func (b Backlog) BySize() []*Group {
 		sg.Stories = append(sg.Stories, v)
 	}
 
+	for _, v := range b.Exemplars {
+		sg, ok := m[v.Size]
+		if ok {
+			sg.Exemplars = append(sg.Exemplars, v)
+		}
+	}
+
 	return gg
 }
 
+// ExemplarsOf returns the exemplars pinned for size.
+func (b Backlog) ExemplarsOf(size Size) []Story {
+	var ss []Story
+	for _, v := range b.Exemplars {
+		if v.Size == size {
+			ss = append(ss, v)
+		}
+	}
+	return ss
+}
+
 // Group represents a grouping of stories.
 type Group struct {
-	Name    string
-	Stories []Story
+	Name      string  `json:"name"`
+	Stories   []Story `json:"stories"`
+	Exemplars []Story `json:"exemplars,omitempty"`
 }
 
 // Story encapsulates all of the core data related to a story.
 type Story struct {
-	Author      string
-	Description string
-	ID          string
-	Size        Size
-	Title       string
+	Author      string `json:"author"`
+	Description string `json:"description"`
+	ID          string `json:"id"`
+	Size        Size   `json:"size,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Title       string `json:"title"`
+}
+
+// DoneStatuses are the workflow statuses of completed stories.
+var DoneStatuses = []string{"Done", "Closed"}
+
+// IsDone returns true when the story is in one of the DoneStatuses.
+func (s Story) IsDone() bool {
+	for _, v := range DoneStatuses {
+		if strings.EqualFold(s.Status, v) {
+			return true
+		}
+	}
+	return false
 }
 
 // Size is a story size type.
 type Size string
 
-var sizes = []Size{Unsized, ExtraSmall, Small, Medium, Large, ExtraLarge, ExtraExtraLarge}
-
 const (
 	// Unsized is a story of an unknown size.
 	Unsized Size = "To Estimate"
@@ -0,0 +1,90 @@
+// Package projecttest provides in-memory implementations of the project interfaces for tests.
+package projecttest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nfisher/wallie/project"
+)
+
+// Client is a project.Client backed by a slice of stories, the user can edit every project.
+type Client struct {
+	Stories []project.Story
+}
+
+// ListStories returns a copy of the stories so callers don't observe later updates.
+func (c *Client) ListStories(projectID string) (project.Backlog, error) {
+	ss := make([]project.Story, len(c.Stories))
+	copy(ss, c.Stories)
+	return project.Backlog{Project: projectID, Stories: ss}, nil
+}
+
+func (c *Client) ListCompleted(projectID string, since time.Time) ([]project.Completed, error) {
+	return nil, nil
+}
+
+func (c *Client) GetStory(projectID, id string) (project.Story, error) {
+	for _, v := range c.Stories {
+		if v.ID == id {
+			return v, nil
+		}
+	}
+	return project.Story{}, nil
+}
+
+func (c *Client) CreateStory(projectID, title, description, size string) (project.Story, error) {
+	story := project.Story{
+		ID:          fmt.Sprintf("%s-%d", projectID, len(c.Stories)+1),
+		Title:       title,
+		Description: description,
+		Size:        project.Size(size),
+	}
+	c.Stories = append(c.Stories, story)
+	return story, nil
+}
+
+// UpdateStory updates the story with id, an empty size leaves the size unchanged.
+func (c *Client) UpdateStory(projectID, id, title, description, size string) error {
+	for i, v := range c.Stories {
+		if v.ID == id {
+			c.Stories[i].Title = title
+			c.Stories[i].Description = description
+			if size != "" {
+				c.Stories[i].Size = project.Size(size)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Client) CanEdit(projectID string) (bool, error) {
+	return true, nil
+}
+
+func (c *Client) User() (string, error) {
+	return "nfisher", nil
+}
+
+// History is a project.History which keeps the changes in memory.
+type History struct {
+	Changes []project.Change
+	// Err is returned by Record when set and the change is discarded.
+	Err error
+}
+
+func (h *History) Record(change project.Change) error {
+	if h.Err != nil {
+		return h.Err
+	}
+	h.Changes = append(h.Changes, change)
+	return nil
+}
+
+func (h *History) Project(projectID string) ([]project.Change, error) {
+	return h.Changes, nil
+}
+
+func (h *History) Story(projectID, id string) ([]project.Change, error) {
+	return h.Changes, nil
+}
@@ -0,0 +1,34 @@
+package project
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrReadOnly is returned when a read-only client is asked to make a change.
+var ErrReadOnly = errors.New("read-only access, log in to make changes")
+
+// ReadOnly wraps c so that every change is rejected with ErrReadOnly.
+func ReadOnly(c Client) Client {
+	return readOnly{c}
+}
+
+type readOnly struct {
+	Client
+}
+
+func (r readOnly) WithContext(ctx context.Context) Client {
+	return readOnly{withContext(r.Client, ctx)}
+}
+
+func (readOnly) CreateStory(projectID, title, description, size string) (Story, error) {
+	return Story{}, ErrReadOnly
+}
+
+func (readOnly) CanEdit(projectID string) (bool, error) {
+	return false, nil
+}
+
+func (readOnly) UpdateStory(projectID, id, title, description, size string) error {
+	return ErrReadOnly
+}
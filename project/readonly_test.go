@@ -0,0 +1,72 @@
+package project_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/project/projecttest"
+)
+
+func Test_ReadOnly_rejects_changes(t *testing.T) {
+	t.Parallel()
+	client := &projecttest.Client{Stories: []project.Story{{ID: "ABC-1", Title: "Add login page"}}}
+	ro := project.ReadOnly(client)
+
+	backlog, err := ro.ListStories("ABC")
+	if err != nil || len(backlog.Stories) != 1 {
+		t.Errorf("got %v stories and err %v, want 1 story and nil", len(backlog.Stories), err)
+	}
+
+	err = ro.UpdateStory("ABC", "ABC-1", "Add login page", "", "S")
+	if err != project.ErrReadOnly {
+		t.Errorf("UpdateStory got err %v, want %v", err, project.ErrReadOnly)
+	}
+	_, err = ro.CreateStory("ABC", "Add logout", "", "")
+	if err != project.ErrReadOnly {
+		t.Errorf("CreateStory got err %v, want %v", err, project.ErrReadOnly)
+	}
+	if client.Stories[0].Size != "" {
+		t.Errorf("got size = %v, want story unchanged", client.Stories[0].Size)
+	}
+}
+
+func Test_KanbanHandler(t *testing.T) {
+	t.Parallel()
+	client := &projecttest.Client{Stories: []project.Story{
+		{ID: "ABC-1", Title: "Add login page", Status: "In Progress"},
+		{ID: "ABC-2", Title: "Create service skeleton", Status: "To Do"},
+	}}
+	fn := func(wallie.Config, []*http.Cookie) project.Client { return client }
+	h := project.KanbanHandler(fn, wallie.Config{})
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/kanban?project=ABC", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status = %v, want 200", w.Code)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"In Progress", "To Do", "ABC-1", "ABC-2"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("got body without %v, want it", want)
+		}
+	}
+}
+
+func Test_WallboardHandler_keeps_share_token(t *testing.T) {
+	t.Parallel()
+	config := wallie.Config{Wallboard: wallie.Wallboard{Projects: []string{"ABC"}}}
+	h := project.WallboardHandler(project.NewWallboard(nil, config), config)
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/wallboard?slide=0&share=123.abc_-", nil))
+
+	want := `url=/wallboard?slide=1&share=123.abc_-"`
+	if !strings.Contains(w.Body.String(), want) {
+		t.Errorf("got no %v in body, want the token passed to the next slide", want)
+	}
+}
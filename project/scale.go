@@ -0,0 +1,151 @@
+package project
+
+import (
+	"strings"
+
+	"github.com/nfisher/wallie"
+)
+
+// Scale is a named estimation scale which maps sizes to story points.
+type Scale struct {
+	Name   string  `json:"name"`
+	Levels []Level `json:"levels"`
+}
+
+// Level is a single size within a scale.
+type Level struct {
+	Size   Size    `json:"size"`
+	Points float64 `json:"points"`
+	// Legacy are additional point values which are read as this size.
+	Legacy []float64 `json:"legacy,omitempty"`
+}
+
+// Sizes returns the sizes of the scale in ascending order.
+func (s Scale) Sizes() []Size {
+	var ss []Size
+	for _, l := range s.levels() {
+		ss = append(ss, l.Size)
+	}
+	return ss
+}
+
+// Points returns the story points for size or false if the size is not part of the scale.
+func (s Scale) Points(size Size) (float64, bool) {
+	for _, l := range s.levels() {
+		if l.Size == size {
+			return l.Points, true
+		}
+	}
+	return 0.0, false
+}
+
+// Size returns the size matching the story points p or Unsized if there is no match.
+func (s Scale) Size(p float64) Size {
+	for _, l := range s.levels() {
+		if l.Points == p {
+			return l.Size
+		}
+		for _, v := range l.Legacy {
+			if v == p {
+				return l.Size
+			}
+		}
+	}
+	return Unsized
+}
+
+func (s Scale) levels() []Level {
+	if len(s.Levels) == 0 {
+		return TShirt.Levels
+	}
+	return s.Levels
+}
+
+var (
+	// TShirt is the default tee-shirt scale with support for the legacy XL and XXL values.
+	TShirt = Scale{
+		Name: "tshirt",
+		Levels: []Level{
+			{Size: ExtraSmall, Points: 1.0},
+			{Size: Small, Points: 2.0},
+			{Size: Medium, Points: 3.0},
+			{Size: Large, Points: 5.0},
+			{Size: ExtraLarge, Points: 8.0, Legacy: []float64{10.0}},
+			{Size: ExtraExtraLarge, Points: 13.0, Legacy: []float64{20.0}},
+		},
+	}
+
+	// Fibonacci is a scale of the Fibonacci sequence up to 21.
+	Fibonacci = Scale{
+		Name: "fibonacci",
+		Levels: []Level{
+			{Size: "1", Points: 1.0},
+			{Size: "2", Points: 2.0},
+			{Size: "3", Points: 3.0},
+			{Size: "5", Points: 5.0},
+			{Size: "8", Points: 8.0},
+			{Size: "13", Points: 13.0},
+			{Size: "21", Points: 21.0},
+		},
+	}
+
+	// PowersOfTwo is a scale of the powers of two up to 32.
+	PowersOfTwo = Scale{
+		Name: "powers",
+		Levels: []Level{
+			{Size: "1", Points: 1.0},
+			{Size: "2", Points: 2.0},
+			{Size: "4", Points: 4.0},
+			{Size: "8", Points: 8.0},
+			{Size: "16", Points: 16.0},
+			{Size: "32", Points: 32.0},
+		},
+	}
+
+	// FitsInSprint is a no-estimate scale that only asks whether a story fits in a sprint.
+	FitsInSprint = Scale{
+		Name: "sprint",
+		Levels: []Level{
+			{Size: "Yes", Points: 1.0},
+			{Size: "No", Points: 13.0},
+		},
+	}
+)
+
+var builtinScales = []Scale{TShirt, Fibonacci, PowersOfTwo, FitsInSprint}
+
+// ScaleFor returns the estimation scale configured for projectID. Scales from the
+// configuration take precedence over the built-in scales and TShirt is used when
+// no scale is found.
+func ScaleFor(config wallie.Config, projectID string) Scale {
+	name, ok := config.ProjectScales[projectID]
+	if !ok {
+		name = config.DefaultScale
+	}
+
+	for _, v := range config.Scales {
+		if strings.EqualFold(v.Name, name) {
+			return configScale(v)
+		}
+	}
+
+	for _, v := range builtinScales {
+		if strings.EqualFold(v.Name, name) {
+			return v
+		}
+	}
+
+	return TShirt
+}
+
+func configScale(cs wallie.Scale) Scale {
+	s := Scale{Name: cs.Name}
+	for _, v := range cs.Sizes {
+		s.Levels = append(s.Levels, Level{
+			Size:   Size(v.Name),
+			Points: v.Points,
+			Legacy: v.Legacy,
+		})
+	}
+	return s
+}
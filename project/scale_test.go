@@ -0,0 +1,105 @@
+package project_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/project"
+)
+
+func Test_Scale_Size(t *testing.T) {
+	t.Parallel()
+
+	td := []struct {
+		points float64
+		size   project.Size
+	}{
+		{0.0, project.Unsized},
+		{1.0, project.ExtraSmall},
+		{2.0, project.Small},
+		{3.0, project.Medium},
+		{5.0, project.Large},
+		{8.0, project.ExtraLarge},
+		{10.0, project.ExtraLarge},
+		{13.0, project.ExtraExtraLarge},
+		{20.0, project.ExtraExtraLarge},
+		{21.0, project.Unsized},
+	}
+
+	for _, tc := range td {
+		actual := project.TShirt.Size(tc.points)
+		if actual != tc.size {
+			t.Errorf("got Size(%v) = %v, want %v", tc.points, actual, tc.size)
+		}
+	}
+}
+
+func Test_Scale_Points(t *testing.T) {
+	t.Parallel()
+
+	p, ok := project.TShirt.Points(project.ExtraLarge)
+	if !ok || p != 8.0 {
+		t.Errorf("got Points(XL) = %v, %v, want 8, true", p, ok)
+	}
+
+	_, ok = project.TShirt.Points(project.Unsized)
+	if ok {
+		t.Error("got Points(Unsized) ok, want !ok")
+	}
+}
+
+func Test_ScaleFor(t *testing.T) {
+	t.Parallel()
+
+	config := wallie.Config{
+		DefaultScale: "Fibonacci",
+		ProjectScales: map[string]string{
+			"ABC": "sprint",
+			"DEF": "yesno",
+			"GHI": "unknown",
+		},
+		Scales: []wallie.Scale{
+			{Name: "yesno", Sizes: []wallie.ScaleSize{{Name: "Y", Points: 1}, {Name: "N", Points: 5}}},
+		},
+	}
+
+	td := []struct {
+		project string
+		sizes   []project.Size
+	}{
+		{"ABC", []project.Size{"Yes", "No"}},
+		{"DEF", []project.Size{"Y", "N"}},
+		{"GHI", project.TShirt.Sizes()},
+		{"XYZ", project.Fibonacci.Sizes()},
+	}
+
+	for _, tc := range td {
+		t.Run(tc.project, func(t *testing.T) {
+			actual := project.ScaleFor(config, tc.project).Sizes()
+			if !reflect.DeepEqual(actual, tc.sizes) {
+				t.Errorf("got Sizes() = %v, want %v", actual, tc.sizes)
+			}
+		})
+	}
+}
+
+func Test_BySize_scale(t *testing.T) {
+	t.Parallel()
+
+	gg := project.Backlog{
+		Scale: project.FitsInSprint,
+		Stories: []project.Story{
+			{Title: "Fits", Size: "Yes"},
+			{Title: "Legacy", Size: project.Medium},
+		},
+	}.BySize()
+
+	if len(gg) != 3 {
+		t.Fatalf("got len = %v, want 3", len(gg))
+	}
+
+	if len(gg[0].Stories) != 1 || len(gg[1].Stories) != 1 {
+		t.Errorf("got %v unsized and %v fits, want 1 and 1", len(gg[0].Stories), len(gg[1].Stories))
+	}
+}
@@ -0,0 +1,13 @@
+package project
+
+// Similarity finds completed stories which are similar to a story.
+type Similarity interface {
+	Index(projectID string, stories []Completed) error
+	Similar(projectID string, story Story, n int) ([]Neighbour, error)
+}
+
+// Neighbour is a sized story and how similar it is, 1 being identical.
+type Neighbour struct {
+	Story
+	Score float64 `json:"score"`
+}
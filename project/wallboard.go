@@ -0,0 +1,273 @@
+package project
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/trace"
+)
+
+// WallboardViews are the views a wallboard can display.
+var WallboardViews = []string{"tshirt", "flow", "kanban"}
+
+const (
+	defaultRotate  = 30 * time.Second
+	defaultRefresh = 5 * time.Minute
+	flowWeeks      = 12
+)
+
+// Wallboard keeps the boards of the configured projects refreshed in the background so
+// kiosk displays never wait on, or log in to, Jira.
+type Wallboard struct {
+	client   func() (Client, error)
+	projects []string
+	views    []string
+	rotate   time.Duration
+	refresh  time.Duration
+
+	sync.RWMutex
+	boards map[string]*Board
+}
+
+// Board is the latest data loaded for a project on the wallboard, Updated is zero until the
+// first refresh completes.
+type Board struct {
+	Project string
+	Backlog Backlog
+	Flow    Flow
+	Updated time.Time
+	Err     string
+}
+
+// Flow summarises how quickly stories are completed.
+type Flow struct {
+	Weeks      []Week
+	MedianDays float64
+}
+
+// Week is the number of stories completed in the week starting at Start.
+type Week struct {
+	Start     time.Time
+	Completed int
+}
+
+// Max returns the largest number of stories completed in a week.
+func (f Flow) Max() int {
+	var max int
+	for _, w := range f.Weeks {
+		if w.Completed > max {
+			max = w.Completed
+		}
+	}
+	return max
+}
+
+// Percent returns the height of the week relative to the busiest week.
+func (f Flow) Percent(w Week) int {
+	max := f.Max()
+	if max == 0 {
+		return 0
+	}
+	return w.Completed * 100 / max
+}
+
+// NewWallboard creates a wallboard for the projects and views in config, client is called on
+// each refresh to obtain a client for the Jira calls.
+func NewWallboard(client func() (Client, error), config wallie.Config) *Wallboard {
+	wb := &Wallboard{
+		client:   client,
+		projects: config.Wallboard.Projects,
+		views:    config.Wallboard.Views,
+		rotate:   time.Duration(config.Wallboard.RotateSeconds) * time.Second,
+		refresh:  time.Duration(config.Wallboard.RefreshSeconds) * time.Second,
+		boards:   make(map[string]*Board),
+	}
+	if len(wb.views) == 0 {
+		wb.views = WallboardViews
+	}
+	if wb.rotate <= 0 {
+		wb.rotate = defaultRotate
+	}
+	if wb.refresh <= 0 {
+		wb.refresh = defaultRefresh
+	}
+	for _, p := range wb.projects {
+		wb.boards[p] = &Board{Project: p}
+	}
+	return wb
+}
+
+// Run refreshes the boards immediately and then on every refresh interval until stop is closed.
+func (wb *Wallboard) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(wb.refresh)
+	defer ticker.Stop()
+
+	for {
+		wb.Refresh(time.Now())
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh reloads the backlog and flow of every project, a project that fails to load
+// keeps its previous data and reports the error.
+func (wb *Wallboard) Refresh(now time.Time) {
+	ctx, span := trace.Start(context.Background(), "wallboard.refresh", trace.Internal)
+	defer span.Finish()
+
+	client, err := wb.client()
+	if err != nil {
+		log.Printf("unable to refresh wallboard: %v\n", err)
+		span.Fail(err)
+		wb.fail(err)
+		return
+	}
+	client = withContext(client, ctx)
+
+	since := startOfWeek(now).AddDate(0, 0, -7*(flowWeeks-1))
+	for _, p := range wb.projects {
+		backlog, err := client.ListStories(p)
+		if err != nil {
+			log.Printf("unable to refresh %v wallboard: %v\n", p, err)
+			wb.failProject(p, err)
+			continue
+		}
+
+		completed, err := client.ListCompleted(p, since)
+		if err != nil {
+			log.Printf("unable to refresh %v wallboard flow: %v\n", p, err)
+			wb.failProject(p, err)
+			continue
+		}
+
+		wb.Lock()
+		wb.boards[p] = &Board{
+			Project: p,
+			Backlog: backlog,
+			Flow:    NewFlow(since, completed),
+			Updated: now,
+		}
+		wb.Unlock()
+	}
+}
+
+func (wb *Wallboard) fail(err error) {
+	for _, p := range wb.projects {
+		wb.failProject(p, err)
+	}
+}
+
+func (wb *Wallboard) failProject(projectID string, err error) {
+	wb.Lock()
+	defer wb.Unlock()
+	b := *wb.boards[projectID]
+	b.Err = err.Error()
+	wb.boards[projectID] = &b
+}
+
+// Slides returns the number of slides the wallboard cycles through.
+func (wb *Wallboard) Slides() int {
+	return len(wb.projects) * len(wb.views)
+}
+
+// Slide is a single view of a project shown on the wallboard.
+type Slide struct {
+	*Board
+	View   string
+	Next   int
+	Rotate int
+	// Share is the token the slide was opened with, it is passed on to the next slide.
+	Share string
+}
+
+// Slide returns the nth slide, n wraps around the number of slides. False is returned when
+// no projects are configured.
+func (wb *Wallboard) Slide(n int) (Slide, bool) {
+	total := wb.Slides()
+	if total == 0 {
+		return Slide{}, false
+	}
+	n = (n%total + total) % total
+
+	wb.RLock()
+	board := wb.boards[wb.projects[n/len(wb.views)]]
+	wb.RUnlock()
+
+	return Slide{
+		Board:  board,
+		View:   wb.views[n%len(wb.views)],
+		Next:   (n + 1) % total,
+		Rotate: int(wb.rotate / time.Second),
+	}, true
+}
+
+// NewFlow counts the stories completed in each week since the start of since's week.
+func NewFlow(since time.Time, completed []Completed) Flow {
+	var flow Flow
+	start := startOfWeek(since)
+	for i := 0; i < flowWeeks; i++ {
+		flow.Weeks = append(flow.Weeks, Week{Start: start.AddDate(0, 0, 7*i)})
+	}
+
+	var days []float64
+	for _, c := range completed {
+		days = append(days, c.Days())
+		if c.Resolved.Before(start) {
+			continue
+		}
+		i := int(c.Resolved.Sub(start).Hours() / (24 * 7))
+		if i < len(flow.Weeks) {
+			flow.Weeks[i].Completed++
+		}
+	}
+
+	if len(days) > 0 {
+		sort.Float64s(days)
+		flow.MedianDays = quantile(days, 0.5)
+	}
+
+	return flow
+}
+
+// startOfWeek returns midnight UTC on the Monday of t's week.
+func startOfWeek(t time.Time) time.Time {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+// Hidden returns the number of stories beyond the first limit stories of the group.
+func (g Group) Hidden(limit int) int {
+	if len(g.Stories) <= limit {
+		return 0
+	}
+	return len(g.Stories) - limit
+}
+
+// ByStatus groups the stories by their workflow status in the order the statuses are first
+// seen in the backlog.
+func (b Backlog) ByStatus() []*Group {
+	var gg []*Group
+	m := make(map[string]*Group)
+	for _, s := range b.Stories {
+		name := s.Status
+		if name == "" {
+			name = "Unknown"
+		}
+		g, ok := m[name]
+		if !ok {
+			g = &Group{Name: name}
+			m[name] = g
+			gg = append(gg, g)
+		}
+		g.Stories = append(g.Stories, s)
+	}
+	return gg
+}
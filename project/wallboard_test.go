@@ -0,0 +1,150 @@
+package project_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nfisher/wallie"
+	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/project/projecttest"
+)
+
+func Test_NewFlow(t *testing.T) {
+	t.Parallel()
+	// Wednesday, the first week starts on Monday 2019-01-07.
+	since := time.Date(2019, 1, 9, 12, 0, 0, 0, time.UTC)
+	completed := []project.Completed{
+		{CycleTime: 24 * time.Hour, Resolved: time.Date(2019, 1, 7, 9, 0, 0, 0, time.UTC)},
+		{CycleTime: 48 * time.Hour, Resolved: time.Date(2019, 1, 13, 23, 0, 0, 0, time.UTC)},
+		{CycleTime: 96 * time.Hour, Resolved: time.Date(2019, 1, 14, 0, 0, 0, 0, time.UTC)},
+	}
+
+	flow := project.NewFlow(since, completed)
+
+	if len(flow.Weeks) != 12 {
+		t.Fatalf("got len(weeks) = %v, want 12", len(flow.Weeks))
+	}
+	if flow.Weeks[0].Start.Format("2006-01-02") != "2019-01-07" {
+		t.Errorf("got first week = %v, want 2019-01-07", flow.Weeks[0].Start)
+	}
+	if flow.Weeks[0].Completed != 2 || flow.Weeks[1].Completed != 1 {
+		t.Errorf("got completed = %v, %v, want 2, 1", flow.Weeks[0].Completed, flow.Weeks[1].Completed)
+	}
+	if flow.MedianDays != 2 {
+		t.Errorf("got median = %v, want 2", flow.MedianDays)
+	}
+	if flow.Percent(flow.Weeks[1]) != 50 {
+		t.Errorf("got percent = %v, want 50", flow.Percent(flow.Weeks[1]))
+	}
+}
+
+func Test_Backlog_ByStatus(t *testing.T) {
+	t.Parallel()
+	backlog := project.Backlog{Stories: []project.Story{
+		{ID: "ABC-1", Status: "In Progress"},
+		{ID: "ABC-2", Status: "To Do"},
+		{ID: "ABC-3", Status: "In Progress"},
+		{ID: "ABC-4"},
+	}}
+
+	groups := backlog.ByStatus()
+
+	var got []string
+	for _, g := range groups {
+		got = append(got, g.Name)
+	}
+	if strings.Join(got, ",") != "In Progress,To Do,Unknown" {
+		t.Errorf("got groups = %v, want In Progress,To Do,Unknown", got)
+	}
+	if len(groups[0].Stories) != 2 {
+		t.Errorf("got len(In Progress) = %v, want 2", len(groups[0].Stories))
+	}
+}
+
+func Test_Wallboard_Slide(t *testing.T) {
+	t.Parallel()
+	config := wallie.Config{Wallboard: wallie.Wallboard{Projects: []string{"ABC", "XYZ"}, Views: []string{"tshirt", "kanban"}, RotateSeconds: 10}}
+	wb := project.NewWallboard(nil, config)
+
+	td := []struct {
+		n       int
+		project string
+		view    string
+		next    int
+	}{
+		{0, "ABC", "tshirt", 1},
+		{1, "ABC", "kanban", 2},
+		{2, "XYZ", "tshirt", 3},
+		{3, "XYZ", "kanban", 0},
+		{4, "ABC", "tshirt", 1},
+		{-1, "XYZ", "kanban", 0},
+	}
+
+	for _, tc := range td {
+		slide, ok := wb.Slide(tc.n)
+		if !ok {
+			t.Fatalf("Slide(%v) got false, want true", tc.n)
+		}
+		if slide.Project != tc.project || slide.View != tc.view || slide.Next != tc.next {
+			t.Errorf("Slide(%v) got %v %v next %v, want %v %v next %v", tc.n, slide.Project, slide.View, slide.Next, tc.project, tc.view, tc.next)
+		}
+		if slide.Rotate != 10 {
+			t.Errorf("Slide(%v) got rotate %v, want 10", tc.n, slide.Rotate)
+		}
+	}
+
+	_, ok := project.NewWallboard(nil, wallie.Config{}).Slide(0)
+	if ok {
+		t.Errorf("got true for a wallboard without projects, want false")
+	}
+}
+
+func Test_Wallboard_Refresh(t *testing.T) {
+	t.Parallel()
+	client := &projecttest.Client{Stories: []project.Story{{ID: "ABC-1", Title: "Add login page", Size: project.Small, Status: "In Progress"}}}
+	var fail bool
+	fn := func() (project.Client, error) {
+		if fail {
+			return nil, errors.New("jira unavailable")
+		}
+		return client, nil
+	}
+	config := wallie.Config{Wallboard: wallie.Wallboard{Projects: []string{"ABC"}}}
+	wb := project.NewWallboard(fn, config)
+	h := project.WallboardHandler(wb, config)
+
+	now := time.Date(2019, 1, 9, 12, 0, 0, 0, time.UTC)
+	wb.Refresh(now)
+	fail = true
+	wb.Refresh(now.Add(time.Minute))
+
+	slide, _ := wb.Slide(0)
+	if !slide.Updated.Equal(now) {
+		t.Errorf("got updated = %v, want %v", slide.Updated, now)
+	}
+	if slide.Err != "jira unavailable" {
+		t.Errorf("got err = %q, want jira unavailable", slide.Err)
+	}
+	if len(slide.Backlog.Stories) != 1 {
+		t.Errorf("got len(stories) = %v, want previous backlog kept", len(slide.Backlog.Stories))
+	}
+
+	for _, slide := range []string{"0", "1", "2"} {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(http.MethodGet, "/wallboard?slide="+slide, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("slide %v got status = %v, want 200", slide, w.Code)
+		}
+		body := w.Body.String()
+		if !strings.Contains(body, "jira unavailable") {
+			t.Errorf("slide %v got no refresh error in body", slide)
+		}
+		if slide == "0" && !strings.Contains(body, `content="30;url=/wallboard?slide=1"`) {
+			t.Errorf("slide %v got no refresh to the next slide", slide)
+		}
+	}
+}
@@ -0,0 +1,480 @@
+// Package qr encodes text as a QR code using byte mode and medium error correction.
+package qr
+
+import (
+	"errors"
+)
+
+// ErrTooLong is returned when the text does not fit in the largest supported version.
+var ErrTooLong = errors.New("qr: text too long to encode")
+
+// maxVersion is the largest supported symbol, version 10 holds 213 bytes.
+const maxVersion = 10
+
+// Error correction codewords per block and number of blocks for level M indexed by version.
+var (
+	eccPerBlock = [maxVersion + 1]int{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26}
+	numBlocks   = [maxVersion + 1]int{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5}
+	alignments  = [maxVersion + 1][]int{
+		nil, nil,
+		{6, 18}, {6, 22}, {6, 26}, {6, 30}, {6, 34},
+		{6, 22, 38}, {6, 24, 42}, {6, 26, 46}, {6, 28, 50},
+	}
+)
+
+// Code is a QR code symbol.
+type Code struct {
+	Size    int
+	modules [][]bool
+}
+
+// Dark returns true when the module at column x and row y is dark.
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// Encode returns the smallest QR code which holds text.
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+
+	for v := 1; v <= maxVersion; v++ {
+		if len(data) > capacity(v) {
+			continue
+		}
+		s := newSymbol(v)
+		s.drawFunctionPatterns()
+		s.drawCodewords(s.interleave(s.dataCodewords(data)))
+		s.applyBestMask()
+		return &Code{Size: s.size, modules: s.modules}, nil
+	}
+
+	return nil, ErrTooLong
+}
+
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// capacity returns the number of bytes a version can hold.
+func capacity(version int) int {
+	return (numDataCodewords(version)*8 - 4 - countBits(version)) / 8
+}
+
+func numRawModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+func numDataCodewords(version int) int {
+	return numRawModules(version)/8 - eccPerBlock[version]*numBlocks[version]
+}
+
+type symbol struct {
+	version    int
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+func newSymbol(version int) *symbol {
+	size := version*4 + 17
+	s := &symbol{version: version, size: size}
+	for i := 0; i < size; i++ {
+		s.modules = append(s.modules, make([]bool, size))
+		s.isFunction = append(s.isFunction, make([]bool, size))
+	}
+	return s
+}
+
+func (s *symbol) setFunction(x, y int, dark bool) {
+	s.modules[y][x] = dark
+	s.isFunction[y][x] = true
+}
+
+func (s *symbol) drawFunctionPatterns() {
+	for i := 0; i < s.size; i++ {
+		s.setFunction(6, i, i%2 == 0)
+		s.setFunction(i, 6, i%2 == 0)
+	}
+
+	s.drawFinder(3, 3)
+	s.drawFinder(s.size-4, 3)
+	s.drawFinder(3, s.size-4)
+
+	pos := alignments[s.version]
+	n := len(pos)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i == 0 && j == 0 || i == 0 && j == n-1 || i == n-1 && j == 0 {
+				continue
+			}
+			s.drawAlignment(pos[i], pos[j])
+		}
+	}
+
+	// reserve the format areas, the real bits are drawn once the mask is chosen.
+	s.drawFormat(0)
+	s.drawVersion()
+}
+
+func (s *symbol) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= s.size || yy < 0 || yy >= s.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			s.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (s *symbol) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			s.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormat draws both copies of the format information for level M and mask.
+func (s *symbol) drawFormat(mask int) {
+	const levelM = 0
+	data := levelM<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	for i := 0; i <= 5; i++ {
+		s.setFunction(8, i, bit(bits, i))
+	}
+	s.setFunction(8, 7, bit(bits, 6))
+	s.setFunction(8, 8, bit(bits, 7))
+	s.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		s.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		s.setFunction(s.size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		s.setFunction(8, s.size-15+i, bit(bits, i))
+	}
+	s.setFunction(8, s.size-8, true)
+}
+
+func (s *symbol) drawVersion() {
+	if s.version < 7 {
+		return
+	}
+
+	rem := s.version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := s.version<<12 | rem
+
+	for i := 0; i < 18; i++ {
+		a := s.size - 11 + i%3
+		b := i / 3
+		s.setFunction(a, b, bit(bits, i))
+		s.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// dataCodewords encodes data in byte mode and pads it to the capacity of the version.
+func (s *symbol) dataCodewords(data []byte) []byte {
+	var bb bitBuffer
+	bb.append(0x4, 4)
+	bb.append(len(data), countBits(s.version))
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+
+	capacityBits := numDataCodewords(s.version) * 8
+	bb.append(0, min(4, capacityBits-len(bb)))
+	bb.append(0, (8-len(bb)%8)%8)
+	for pad := 0xEC; len(bb) < capacityBits; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+
+	codewords := make([]byte, len(bb)/8)
+	for i, b := range bb {
+		if b {
+			codewords[i>>3] |= 1 << uint(7-i&7)
+		}
+	}
+	return codewords
+}
+
+// interleave splits data into blocks, appends the error correction to each block and
+// interleaves the blocks.
+func (s *symbol) interleave(data []byte) []byte {
+	blocks := numBlocks[s.version]
+	eccLen := eccPerBlock[s.version]
+	raw := numRawModules(s.version) / 8
+	numShort := blocks - raw%blocks
+	shortLen := raw / blocks
+
+	divisor := rsDivisor(eccLen)
+	var bb [][]byte
+	k := 0
+	for i := 0; i < blocks; i++ {
+		n := shortLen - eccLen
+		if i >= numShort {
+			n++
+		}
+		dat := append([]byte{}, data[k:k+n]...)
+		k += n
+		ecc := rsRemainder(dat, divisor)
+		if i < numShort {
+			dat = append(dat, 0)
+		}
+		bb = append(bb, append(dat, ecc...))
+	}
+
+	var result []byte
+	for i := range bb[0] {
+		for j := range bb {
+			if i != shortLen-eccLen || j >= numShort {
+				result = append(result, bb[j][i])
+			}
+		}
+	}
+	return result
+}
+
+func (s *symbol) drawCodewords(data []byte) {
+	i := 0
+	for right := s.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < s.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				upward := (right+1)&2 == 0
+				y := vert
+				if upward {
+					y = s.size - 1 - vert
+				}
+				if !s.isFunction[y][x] && i < len(data)*8 {
+					s.modules[y][x] = bit(int(data[i>>3]), 7-i&7)
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (s *symbol) applyMask(mask int) {
+	for y := 0; y < s.size; y++ {
+		for x := 0; x < s.size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !s.isFunction[y][x] {
+				s.modules[y][x] = !s.modules[y][x]
+			}
+		}
+	}
+}
+
+// applyBestMask applies the mask with the lowest penalty, masks are their own inverse so
+// each candidate is removed again after scoring.
+func (s *symbol) applyBestMask() {
+	best, bestPenalty := 0, -1
+	for m := 0; m < 8; m++ {
+		s.applyMask(m)
+		s.drawFormat(m)
+		p := s.penalty()
+		if bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = m, p
+		}
+		s.applyMask(m)
+	}
+	s.applyMask(best)
+	s.drawFormat(best)
+}
+
+// penalty scores the symbol using the four rules of the QR specification.
+func (s *symbol) penalty() int {
+	var result, dark int
+
+	line := func(get func(i int) bool) {
+		run := 1
+		for i := 1; i < s.size; i++ {
+			if get(i) == get(i-1) {
+				run++
+				continue
+			}
+			if run >= 5 {
+				result += 3 + run - 5
+			}
+			run = 1
+		}
+		if run >= 5 {
+			result += 3 + run - 5
+		}
+
+		// finder-like 1:1:3:1:1 patterns with four light modules on either side.
+		pattern := []bool{true, false, true, true, true, false, true}
+		for i := 0; i+7 <= s.size; i++ {
+			match := true
+			for k, v := range pattern {
+				if get(i+k) != v {
+					match = false
+					break
+				}
+			}
+			if !match {
+				continue
+			}
+			if lightRun(get, i-4, i, s.size) || lightRun(get, i+7, i+11, s.size) {
+				result += 40
+			}
+		}
+	}
+
+	for y := 0; y < s.size; y++ {
+		line(func(i int) bool { return s.modules[y][i] })
+	}
+	for x := 0; x < s.size; x++ {
+		line(func(i int) bool { return s.modules[i][x] })
+	}
+
+	for y := 0; y < s.size-1; y++ {
+		for x := 0; x < s.size-1; x++ {
+			c := s.modules[y][x]
+			if c == s.modules[y][x+1] && c == s.modules[y+1][x] && c == s.modules[y+1][x+1] {
+				result += 3
+			}
+		}
+	}
+
+	for y := 0; y < s.size; y++ {
+		for x := 0; x < s.size; x++ {
+			if s.modules[y][x] {
+				dark++
+			}
+		}
+	}
+	total := s.size * s.size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	result += k * 10
+
+	return result
+}
+
+// lightRun returns true when every module in [from, to) is light, modules outside of the
+// symbol count as light.
+func lightRun(get func(int) bool, from, to, size int) bool {
+	for i := from; i < to; i++ {
+		if i >= 0 && i < size && get(i) {
+			return false
+		}
+	}
+	return true
+}
+
+type bitBuffer []bool
+
+func (bb *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*bb = append(*bb, (v>>uint(i))&1 != 0)
+	}
+}
+
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coef := range divisor {
+			result[i] ^= gfMultiply(coef, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+	return byte(z)
+}
+
+func bit(x, i int) bool {
+	return (x>>uint(i))&1 != 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,101 @@
+package qr_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie/qr"
+)
+
+func Test_Encode_matches_reference_symbol(t *testing.T) {
+	t.Parallel()
+	want := []string{
+		"#######....##.#..####.#######",
+		"#.....#.......####..#.#.....#",
+		"#.###.#.#.#....##..#..#.###.#",
+		"#.###.#.#.####...#....#.###.#",
+		"#.###.#.#..##.#..####.#.###.#",
+		"#.....#.#.##.#.#......#.....#",
+		"#######.#.#.#.#.#.#.#.#######",
+		"........#...#..#...##........",
+		"#.#####...#.#..#####..#####..",
+		"###.#..##...##.#####.##.#...#",
+		"..###.#..#.#.#####..#.###....",
+		"...##...##.###.##...###.##.#.",
+		"#...####.##...#..#.......##..",
+		"###..#...#.#...##.#######...#",
+		"###.#.##...##..#.##......##..",
+		"..#..#...#.##.###.#.#...#..#.",
+		"#...###.#.#....#.#.#...#.##..",
+		"##..##.#.##..####..##.###.#.#",
+		"#..####.#...#######..####.#..",
+		"#..##..#...###..#.#..#.....#.",
+		"#..#####.##.#.#..#..#####.###",
+		"........####.....####...#####",
+		"#######..#..#..###.##.#.###..",
+		"#.....#.#####.###..##...#..##",
+		"#.###.#.#..#...#.#..#####.#..",
+		"#.###.#.##.#.##.#..#.....####",
+		"#.###.#.####.#.###.#########.",
+		"#.....#..##.#.......##...#.#.",
+		"#######.#.#..#####.#......#..",
+	}
+
+	code, err := qr.Encode("https://jira.example.com/browse/DMP-42")
+	if err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	if code.Size != len(want) {
+		t.Fatalf("got size %v, want %v", code.Size, len(want))
+	}
+
+	for y, row := range want {
+		var got strings.Builder
+		for x := 0; x < code.Size; x++ {
+			if code.Dark(x, y) {
+				got.WriteByte('#')
+			} else {
+				got.WriteByte('.')
+			}
+		}
+		if got.String() != row {
+			t.Errorf("row %v got %v, want %v", y, got.String(), row)
+		}
+	}
+}
+
+func Test_Encode_size(t *testing.T) {
+	t.Parallel()
+	td := []struct {
+		name string
+		len  int
+		size int
+	}{
+		{"version 1", 14, 21},
+		{"version 2", 15, 25},
+		{"version 7", 122, 45},
+		{"version 10", 213, 57},
+	}
+
+	for _, tc := range td {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			code, err := qr.Encode(strings.Repeat("a", tc.len))
+			if err != nil {
+				t.Fatalf("got err %v, want nil", err)
+			}
+			if code.Size != tc.size {
+				t.Errorf("got size %v, want %v", code.Size, tc.size)
+			}
+		})
+	}
+}
+
+func Test_Encode_too_long(t *testing.T) {
+	t.Parallel()
+	_, err := qr.Encode(strings.Repeat("a", 214))
+	if err != qr.ErrTooLong {
+		t.Errorf("got err %v, want %v", err, qr.ErrTooLong)
+	}
+}
@@ -0,0 +1,90 @@
+// Package redirect remembers where a user was going before they were asked to log in.
+package redirect
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CookieName is the cookie the signed target is stored in.
+const CookieName = "wallieRedirect"
+
+// maxTarget is the longest target which is remembered.
+const maxTarget = 2048
+
+// Safe returns true when target is a relative path on this server. Absolute URLs,
+// scheme-relative URLs and anything a browser could read as another host are rejected.
+func Safe(target string) bool {
+	if len(target) == 0 || len(target) > maxTarget || target[0] != '/' {
+		return false
+	}
+	if strings.HasPrefix(target, "//") || strings.ContainsAny(target, "\\") {
+		return false
+	}
+	for _, r := range target {
+		// browsers strip tabs and newlines from URLs, "/\t/evil.com" becomes "//evil.com".
+		if r < 0x20 || r == 0x7f {
+			return false
+		}
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" && u.Host == "" && u.User == nil && strings.HasPrefix(u.Path, "/")
+}
+
+// Cookie returns a cookie holding target signed with secret, nil is returned when target
+// is not Safe.
+func Cookie(secret []byte, target string, secure bool) *http.Cookie {
+	if !Safe(target) {
+		return nil
+	}
+
+	value := base64.RawURLEncoding.EncodeToString([]byte(target))
+	return &http.Cookie{
+		Name:     CookieName,
+		Value:    value + "." + sign(secret, value),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// Clear returns a cookie which removes the stored target.
+func Clear() *http.Cookie {
+	return &http.Cookie{Name: CookieName, Path: "/", MaxAge: -1}
+}
+
+// Target returns the target stored in the cookie of req, fallback is returned when the
+// cookie is missing, has been tampered with or its target is not Safe.
+func Target(secret []byte, req *http.Request, fallback string) string {
+	c, err := req.Cookie(CookieName)
+	if err != nil {
+		return fallback
+	}
+
+	parts := strings.SplitN(c.Value, ".", 2)
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(sign(secret, parts[0]))) {
+		return fallback
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || !Safe(string(b)) {
+		return fallback
+	}
+
+	return string(b)
+}
+
+func sign(secret []byte, value string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("redirect\n" + value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
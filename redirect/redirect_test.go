@@ -0,0 +1,96 @@
+package redirect_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie/redirect"
+)
+
+func Test_Safe(t *testing.T) {
+	t.Parallel()
+	td := []struct {
+		target string
+		safe   bool
+	}{
+		{"/tshirt?project=DMP", true},
+		{"/history?project=DMP&id=DMP-1", true},
+		{"/", true},
+		{"", false},
+		{"tshirt", false},
+		{"evil.com", false},
+		{"//evil.com", false},
+		{"///evil.com", false},
+		{"/\\evil.com", false},
+		{"\\\\evil.com", false},
+		{"/\t/evil.com", false},
+		{"/\n/evil.com", false},
+		{"/%0d%0aSet-Cookie:x=1", true},
+		{"https://evil.com/tshirt", false},
+		{"http:/evil.com", false},
+		{"javascript:alert(1)", false},
+		{"/javascript:alert(1)", true},
+		{" /tshirt", false},
+		{"/" + strings.Repeat("a", 2048), false},
+	}
+
+	for _, tc := range td {
+		got := redirect.Safe(tc.target)
+		if got != tc.safe {
+			t.Errorf("Safe(%q) got %v, want %v", tc.target, got, tc.safe)
+		}
+	}
+}
+
+func Test_Target(t *testing.T) {
+	t.Parallel()
+	secret := []byte("secret")
+	valid := redirect.Cookie(secret, "/tshirt?project=DMP", true)
+	forged := redirect.Cookie([]byte("other"), "//evil.com/x", true)
+	if forged != nil {
+		t.Fatalf("got cookie for an unsafe target, want nil")
+	}
+	other := redirect.Cookie([]byte("other"), "/evil", true)
+
+	td := []struct {
+		name   string
+		cookie *http.Cookie
+		want   string
+	}{
+		{"valid", valid, "/tshirt?project=DMP"},
+		{"missing", nil, "/landing"},
+		{"unsigned", &http.Cookie{Name: redirect.CookieName, Value: "/tshirt"}, "/landing"},
+		{"plain url", &http.Cookie{Name: redirect.CookieName, Value: "https://evil.com"}, "/landing"},
+		{"other secret", other, "/landing"},
+		{"tampered target", &http.Cookie{Name: redirect.CookieName, Value: "Ly9ldmlsLmNvbQ" + valid.Value[strings.Index(valid.Value, "."):]}, "/landing"},
+		{"bad encoding", &http.Cookie{Name: redirect.CookieName, Value: "!!!." + valid.Value[strings.Index(valid.Value, ".")+1:]}, "/landing"},
+	}
+
+	for _, tc := range td {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodPost, "/login", nil)
+			if tc.cookie != nil {
+				req.AddCookie(&http.Cookie{Name: tc.cookie.Name, Value: tc.cookie.Value})
+			}
+			got := redirect.Target(secret, req, "/landing")
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func Test_Cookie(t *testing.T) {
+	t.Parallel()
+	c := redirect.Cookie([]byte("secret"), "/tshirt?project=DMP", true)
+	if !c.HttpOnly || !c.Secure || c.Path != "/" || c.SameSite != http.SameSiteLaxMode {
+		t.Errorf("got cookie %+v, want HttpOnly, Secure, SameSite=Lax and Path=/", c)
+	}
+	if strings.ContainsAny(c.Value, ";, \"") {
+		t.Errorf("got value %q, want a cookie safe value", c.Value)
+	}
+}
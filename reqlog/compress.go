@@ -0,0 +1,196 @@
+package reqlog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// minCompressSize is the smallest response with a known length worth compressing.
+const minCompressSize = 1024
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		gz, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return gz
+	},
+}
+
+// Compress gzips text responses for clients which accept it. Flushes are passed through
+// the compressor so pages streamed in parts still reach the browser as they are written.
+// Brotli isn't offered as the standard library has no encoder for it.
+// Secrets rendered next to reflected input must differ on every response, as the CSRF
+// tokens do, or their compressed length would give them away (BREACH).
+func Compress(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if req.Method == http.MethodHead || req.Header.Get("Range") != "" || !acceptsGzip(req) {
+			h.ServeHTTP(w, req)
+			return
+		}
+
+		gw := &gzipWriter{ResponseWriter: w}
+		defer gw.Close()
+		h.ServeHTTP(gw, req)
+	})
+}
+
+// acceptsGzip returns true when the Accept-Encoding header allows gzip.
+func acceptsGzip(req *http.Request) bool {
+	for _, enc := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		parts := strings.Split(enc, ";")
+		name := strings.ToLower(strings.TrimSpace(parts[0]))
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		q := 1.0
+		for _, p := range parts[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				v, err := strconv.ParseFloat(p[2:], 64)
+				if err == nil {
+					q = v
+				}
+			}
+		}
+		return q > 0
+	}
+	return false
+}
+
+// compressible returns true for the text formats served by the application.
+func compressible(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mt, "text/"):
+		return true
+	case mt == "application/json", mt == "application/javascript", mt == "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// gzipWriter decides whether to compress when the header is written and compresses every
+// write after that.
+type gzipWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (w *gzipWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.wroteHeader = true
+
+	h := w.Header()
+	if w.shouldCompress(code, h) {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", "gzip")
+		w.gz = gzipWriters.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipWriter) shouldCompress(code int, h http.Header) bool {
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		return false
+	}
+	if h.Get("Content-Encoding") != "" || !compressible(h.Get("Content-Type")) {
+		return false
+	}
+	if n, err := strconv.Atoi(h.Get("Content-Length")); err == nil && n < minCompressSize {
+		return false
+	}
+	return true
+}
+
+func (w *gzipWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		// sniff before compressing as the server would otherwise sniff compressed bytes.
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.gz.Write(p)
+}
+
+// ReadFrom copies r through the compressor, uncompressed responses use the wrapped
+// ResponseWriter's ReadFrom.
+func (w *gzipWriter) ReadFrom(r io.Reader) (int64, error) {
+	if !w.wroteHeader {
+		br := bufio.NewReaderSize(r, 512)
+		sniff, _ := br.Peek(512)
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(sniff))
+		}
+		w.WriteHeader(http.StatusOK)
+		r = br
+	}
+	if w.gz == nil {
+		if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+			return rf.ReadFrom(r)
+		}
+		return io.Copy(writerOnly{w.ResponseWriter}, r)
+	}
+	return io.Copy(writerOnly{w.gz}, r)
+}
+
+// Flush writes the compressed data buffered so far and flushes it to the client.
+func (w *gzipWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack is only possible before the response has been started.
+func (w *gzipWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok || w.gz != nil {
+		return nil, nil, ErrNotHijacker
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Close completes the compressed stream.
+func (w *gzipWriter) Close() error {
+	if w.gz == nil || w.hijacked {
+		return nil
+	}
+	err := w.gz.Close()
+	gzipWriters.Put(w.gz)
+	w.gz = nil
+	return err
+}
+
+// Unwrap returns the wrapped ResponseWriter.
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,135 @@
+package reqlog_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nfisher/wallie/reqlog"
+)
+
+func Test_Compress(t *testing.T) {
+	t.Parallel()
+	page := strings.Repeat("<p>story</p>", 200)
+	td := []struct {
+		name     string
+		accept   string
+		header   map[string]string
+		body     string
+		encoding string
+	}{
+		{"html", "gzip, deflate", nil, page, "gzip"},
+		{"sniffed html", "gzip", nil, "<!DOCTYPE html>" + page, "gzip"},
+		{"not accepted", "deflate", nil, page, ""},
+		{"refused", "gzip;q=0", nil, page, ""},
+		{"wildcard", "*", nil, page, "gzip"},
+		{"small json", "gzip", map[string]string{"Content-Type": "application/json", "Content-Length": "2"}, "{}", ""},
+		{"image", "gzip", map[string]string{"Content-Type": "image/png"}, page, ""},
+		{"encoded", "gzip", map[string]string{"Content-Type": "text/html", "Content-Encoding": "br"}, page, "br"},
+	}
+
+	for _, tc := range td {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			h := reqlog.Compress(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				for k, v := range tc.header {
+					w.Header().Set(k, v)
+				}
+				w.Write([]byte(tc.body))
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/tshirt", nil)
+			req.Header.Set("Accept-Encoding", tc.accept)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			got := w.Header().Get("Content-Encoding")
+			if got != tc.encoding {
+				t.Fatalf("got Content-Encoding %q, want %q", got, tc.encoding)
+			}
+			if w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("got Vary %q, want Accept-Encoding", w.Header().Get("Vary"))
+			}
+			body := w.Body.String()
+			if got == "gzip" {
+				body = gunzip(t, w.Body.Bytes())
+			}
+			if body != tc.body {
+				t.Errorf("got body of %v bytes, want %v bytes", len(body), len(tc.body))
+			}
+		})
+	}
+}
+
+func gunzip(t *testing.T, b []byte) string {
+	t.Helper()
+	r, err := gzip.NewReader(strings.NewReader(string(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+// Test_Compress_streaming checks the head of a page reaches the client before the rest of
+// the page has been written through every middleware which wraps the ResponseWriter.
+func Test_Compress_streaming(t *testing.T) {
+	t.Parallel()
+	release := make(chan struct{})
+	route := func(*http.Request) string { return "/tshirt" }
+	h := reqlog.LogRequests(reqlog.Measure(reqlog.Compress(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<head></head>\n"))
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			t.Error("got no http.Flusher, want the ResponseWriter to flush")
+			return
+		}
+		flusher.Flush()
+		<-release
+		w.Write([]byte("<body></body>\n"))
+	})), route))
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("got Content-Encoding %q, want gzip", resp.Header.Get("Content-Encoding"))
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	head := make(chan string)
+	go func() {
+		line, _ := bufio.NewReader(gz).ReadString('\n')
+		head <- line
+	}()
+
+	select {
+	case line := <-head:
+		if line != "<head></head>\n" {
+			t.Errorf("got %q, want <head></head>", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("got no head before the body was written, want it flushed")
+	}
+	close(release)
+}
@@ -1,50 +1,334 @@
+// Package reqlog logs and measures the requests served by the application.
 package reqlog
 
 import (
-	"log"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/nfisher/wallie/share"
+	"github.com/nfisher/wallie/trace"
+)
+
+// HeaderName is the header a request ID is read from and returned in.
+const HeaderName = "X-Request-ID"
+
+// Format is the layout of a logged request.
+type Format string
+
+// Formats supported by Logger.
+const (
+	Text     Format = "text"
+	JSON     Format = "json"
+	Logfmt   Format = "logfmt"
+	Combined Format = "combined"
+)
+
+// Optional fields of the json and logfmt formats.
+const (
+	FieldUser    = "user"
+	FieldProject = "project"
+	FieldBytes   = "bytes"
+	FieldLatency = "latency"
 )
 
+// Fields are all of the optional fields in the order they're logged.
+var Fields = []string{FieldUser, FieldProject, FieldBytes, FieldLatency}
+
+// Logger writes a line for each request it handles.
+type Logger struct {
+	Format Format
+	// Out receives the lines of every format, it defaults to stdout where the server logs.
+	Out io.Writer
+	// User returns the name of the user making the request, it may return an empty string.
+	User func(*http.Request) string
+	// Project returns the project a request is for, it defaults to the project parameter.
+	Project func(*http.Request) string
+
+	fields map[string]bool
+	skip   map[string]bool
+	mu     sync.Mutex
+}
+
+// New returns a Logger writing format to stdout. fields limits the optional fields which
+// are logged, all fields are logged when it's empty. Requests for the skip paths are not
+// logged.
+func New(format Format, fields, skip []string) (*Logger, error) {
+	switch format {
+	case "":
+		format = Text
+	case Text, JSON, Logfmt, Combined:
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+
+	if len(fields) == 0 {
+		fields = Fields
+	}
+	l := &Logger{
+		Format:  format,
+		Out:     os.Stdout,
+		Project: queryProject,
+		fields:  make(map[string]bool),
+		skip:    make(map[string]bool),
+	}
+	for _, f := range fields {
+		if !known(f) {
+			return nil, fmt.Errorf("unknown log field %q", f)
+		}
+		l.fields[f] = true
+	}
+	for _, p := range skip {
+		l.skip[p] = true
+	}
+
+	return l, nil
+}
+
+func known(field string) bool {
+	for _, f := range Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func queryProject(req *http.Request) string {
+	return req.URL.Query().Get("project")
+}
+
+// LogRequests logs every request in the text format.
 func LogRequests(h http.Handler) http.Handler {
+	l, _ := New(Text, nil, nil)
+	return l.Handler(h)
+}
+
+// Handler assigns each request an ID, serves it with h in a span continuing the caller's
+// trace and logs it.
+func (l *Logger) Handler(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(HeaderName)
+		if !validID(id) {
+			id = newID()
+		}
+		w.Header().Set(HeaderName, id)
+		ctx := context.WithValue(req.Context(), idKey{}, id)
+		ctx, span := trace.Start(trace.Extract(ctx, req.Header), req.Method+" "+req.URL.Path, trace.Server)
+		req = req.WithContext(ctx)
+
 		wr := &ResponseWriter{ResponseWriter: w}
 		start := time.Now()
 		h.ServeHTTP(wr, req)
-		log.Printf(`%v %s %s - %v - %v - %vB`, wr.Status(), req.Method, req.URL.Path, req.RemoteAddr, time.Now().Sub(start), wr.Bytes())
+
+		status := wr.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.Set("http.method", req.Method)
+		span.Set("http.target", req.URL.Path)
+		span.Set("http.status_code", status)
+		span.Set("request.id", id)
+		if status >= http.StatusInternalServerError {
+			span.Fail(errors.New(http.StatusText(status)))
+		}
+		span.Finish()
+
+		if l.skip[req.URL.Path] {
+			return
+		}
+		l.log(req, wr, id, start, time.Now().Sub(start))
 	})
 }
 
-type ResponseWriter struct {
-	http.ResponseWriter
-	bytes       int
-	status      int
-	wroteHeader bool
+type idKey struct{}
+
+// RequestID returns the ID of the request ctx belongs to.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(idKey{}).(string)
+	return id
 }
 
-func (w *ResponseWriter) Status() int {
-	return w.status
+// validID accepts the IDs of proxies and other services while keeping log lines readable.
+func validID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
 }
 
-func (w *ResponseWriter) Bytes() int {
-	return w.bytes
+func newID() string {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	if err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
 }
 
-func (w *ResponseWriter) Write(p []byte) (n int, err error) {
-	if !w.wroteHeader {
-		w.WriteHeader(http.StatusOK)
+func (l *Logger) log(req *http.Request, wr *ResponseWriter, id string, start time.Time, latency time.Duration) {
+	status := wr.Status()
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	var user string
+	if l.User != nil {
+		user = l.User(req)
+	}
+
+	var line string
+	switch l.Format {
+	case Text:
+		// the timestamp matches the server log lines written by the standard logger.
+		line = fmt.Sprintf(`%s %v %s %s - %v - %v - %vB`, start.UTC().Format("2006/01/02 15:04:05"), status, req.Method, req.URL.Path, req.RemoteAddr, latency, wr.Bytes())
+	case Combined:
+		line = combined(req, user, status, wr.Bytes(), start)
+	default:
+		entry := []pair{
+			{"time", start.UTC().Format(time.RFC3339Nano)},
+			{"request_id", id},
+			{"method", req.Method},
+			{"path", req.URL.Path},
+			{"status", status},
+			{"remote", req.RemoteAddr},
+		}
+		if span := trace.FromContext(req.Context()); span != nil {
+			entry = append(entry, pair{"trace_id", span.TraceID.String()})
+		}
+		if l.fields[FieldUser] {
+			entry = append(entry, pair{"user", user})
+		}
+		if l.fields[FieldProject] && l.Project != nil {
+			entry = append(entry, pair{"project", l.Project(req)})
+		}
+		if l.fields[FieldBytes] {
+			entry = append(entry, pair{"bytes", wr.Bytes()})
+		}
+		if l.fields[FieldLatency] {
+			entry = append(entry, pair{"latency_ms", float64(latency) / float64(time.Millisecond)})
+		}
+		if l.Format == JSON {
+			line = jsonLine(entry)
+		} else {
+			line = logfmtLine(entry)
+		}
 	}
-	w.bytes += len(p)
 
-	return w.ResponseWriter.Write(p)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	io.WriteString(l.Out, line+"\n")
+}
+
+type pair struct {
+	key   string
+	value interface{}
+}
+
+func jsonLine(entry []pair) string {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, p := range entry {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		k, _ := json.Marshal(p.key)
+		v, _ := json.Marshal(p.value)
+		sb.Write(k)
+		sb.WriteByte(':')
+		sb.Write(v)
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func logfmtLine(entry []pair) string {
+	var sb strings.Builder
+	for i, p := range entry {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(p.key)
+		sb.WriteByte('=')
+		switch v := p.value.(type) {
+		case string:
+			if v == "" || strings.ContainsAny(v, " =\"\\") || strings.IndexFunc(v, isControl) >= 0 {
+				v = strconv.Quote(v)
+			}
+			sb.WriteString(v)
+		case float64:
+			sb.WriteString(strconv.FormatFloat(v, 'f', 3, 64))
+		default:
+			fmt.Fprint(&sb, v)
+		}
+	}
+	return sb.String()
+}
+
+func isControl(r rune) bool {
+	return r < ' ' || r == 0x7f
+}
+
+// combined formats the request in the Apache Combined Log Format. Share tokens are removed
+// from the query as anyone reading the log could use them.
+func combined(req *http.Request, user string, status, bytes int, start time.Time) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	u := *req.URL
+	q := u.Query()
+	if q.Get(share.Param) != "" {
+		q.Set(share.Param, "redacted")
+		u.RawQuery = q.Encode()
+	}
+
+	size := "-"
+	if bytes > 0 {
+		size = strconv.Itoa(bytes)
+	}
+
+	return fmt.Sprintf(`%s - %s [%s] %s %d %s %s %s`,
+		dash(host),
+		dash(user),
+		start.Format("02/Jan/2006:15:04:05 -0700"),
+		quote(req.Method+" "+u.RequestURI()+" "+req.Proto),
+		status,
+		size,
+		quote(req.Referer()),
+		quote(req.UserAgent()))
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return strings.Replace(s, " ", "_", -1)
 }
 
-func (w *ResponseWriter) WriteHeader(code int) {
-	w.ResponseWriter.WriteHeader(code)
-	// Check after in case there's error handling in the wrapped ResponseWriter.
-	if w.wroteHeader {
-		return
+// quote escapes s so it can't end the quoted field or the line.
+func quote(s string) string {
+	if s == "" {
+		return `"-"`
 	}
-	w.status = code
-	w.wroteHeader = true
+	return strconv.Quote(s)
 }
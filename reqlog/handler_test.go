@@ -0,0 +1,43 @@
+package reqlog_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie/reqlog"
+)
+
+func Test_Logger_output(t *testing.T) {
+	t.Parallel()
+	td := []struct {
+		format reqlog.Format
+		want   string
+	}{
+		{reqlog.Text, "200 GET /tshirt"},
+		{reqlog.JSON, `"path":"/tshirt"`},
+		{reqlog.Logfmt, "path=/tshirt"},
+		{reqlog.Combined, `"GET /tshirt HTTP/1.1" 200`},
+	}
+
+	for _, tc := range td {
+		tc := tc
+		t.Run(string(tc.format), func(t *testing.T) {
+			t.Parallel()
+			l, err := reqlog.New(tc.format, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			l.Out = &out
+
+			h := l.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/tshirt", nil))
+			if !strings.Contains(out.String(), tc.want) {
+				t.Errorf("got %q, want a line containing %q", out.String(), tc.want)
+			}
+		})
+	}
+}
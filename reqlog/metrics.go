@@ -0,0 +1,48 @@
+package reqlog
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nfisher/wallie/metrics"
+)
+
+var (
+	requests = metrics.Default.NewCounter("wallie_http_requests_total",
+		"HTTP requests served by route, method and status.", "route", "method", "status")
+	latency = metrics.Default.NewHistogram("wallie_http_request_duration_seconds",
+		"Time taken to serve HTTP requests by route and method.", metrics.DefBuckets, "route", "method")
+)
+
+// Measure records the count and latency of the requests served by h. route names the
+// handler a request is served by and should only return a small set of values.
+func Measure(h http.Handler, route func(*http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		wr := &ResponseWriter{ResponseWriter: w}
+		start := time.Now()
+		h.ServeHTTP(wr, req)
+
+		r := route(req)
+		if r == "" {
+			r = "unmatched"
+		}
+		status := wr.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		m := method(req.Method)
+		requests.Inc(r, m, strconv.Itoa(status))
+		latency.Observe(time.Since(start).Seconds(), r, m)
+	})
+}
+
+// method limits the method label to the standard methods.
+func method(m string) string {
+	switch m {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions:
+		return m
+	}
+	return "OTHER"
+}
@@ -0,0 +1,99 @@
+package reqlog
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+)
+
+// ErrNotHijacker is returned when the wrapped ResponseWriter doesn't support hijacking.
+var ErrNotHijacker = errors.New("reqlog: response writer does not support hijacking")
+
+// ResponseWriter records the status and size of a response. It passes flushes, hijacks
+// and ReadFrom through to the ResponseWriter it wraps so streaming keeps working.
+type ResponseWriter struct {
+	http.ResponseWriter
+	bytes       int
+	status      int
+	wroteHeader bool
+}
+
+func (w *ResponseWriter) Status() int {
+	return w.status
+}
+
+func (w *ResponseWriter) Bytes() int {
+	return w.bytes
+}
+
+func (w *ResponseWriter) Write(p []byte) (n int, err error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err = w.ResponseWriter.Write(p)
+	w.bytes += n
+
+	return n, err
+}
+
+func (w *ResponseWriter) WriteHeader(code int) {
+	w.ResponseWriter.WriteHeader(code)
+	// Check after in case there's error handling in the wrapped ResponseWriter.
+	if w.wroteHeader {
+		return
+	}
+	w.status = code
+	w.wroteHeader = true
+}
+
+// Flush sends any buffered data to the client, it does nothing when the wrapped
+// ResponseWriter can't flush.
+func (w *ResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection when the wrapped ResponseWriter allows.
+func (w *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, ErrNotHijacker
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && !w.wroteHeader {
+		w.status = http.StatusSwitchingProtocols
+		w.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// ReadFrom copies r to the response using the wrapped ResponseWriter's ReadFrom when it
+// has one so files can be sent without copying them through user space.
+func (w *ResponseWriter) ReadFrom(r io.Reader) (n int64, err error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		n, err = io.Copy(writerOnly{w.ResponseWriter}, r)
+	}
+	w.bytes += int(n)
+	return n, err
+}
+
+// Unwrap returns the wrapped ResponseWriter.
+func (w *ResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// writerOnly hides the ReadFrom of a writer so io.Copy doesn't recurse into it.
+type writerOnly struct {
+	io.Writer
+}
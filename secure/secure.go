@@ -0,0 +1,127 @@
+// Package secure sets the security headers of every response. Its Content-Security-Policy
+// only runs inline scripts which carry the nonce of the request.
+package secure
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultReferrerPolicy keeps share tokens and paths from the sites pages link to.
+	DefaultReferrerPolicy = "same-origin"
+	// DefaultHSTSSeconds is the max-age of Strict-Transport-Security, one year.
+	DefaultHSTSSeconds = 365 * 24 * 60 * 60
+)
+
+type nonceKey struct{}
+
+// Headers sets the security headers of the responses it handles.
+type Headers struct {
+	// FrameAncestors are the origins allowed to show the pages in a frame, for example a
+	// kiosk dashboard. The pages can't be framed when it's empty.
+	FrameAncestors []string
+	// ReferrerPolicy defaults to DefaultReferrerPolicy.
+	ReferrerPolicy string
+	// HSTSSeconds is the max-age of Strict-Transport-Security sent with HTTPS responses,
+	// zero uses DefaultHSTSSeconds and a negative value omits the header.
+	HSTSSeconds int
+	// ReportOnly sends the policy as Content-Security-Policy-Report-Only so its effect can
+	// be checked in the browser console without blocking anything.
+	ReportOnly bool
+}
+
+// Handler adds a nonce to the context of each request and sets the security headers.
+func (s *Headers) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		nonce := newNonce()
+		req = req.WithContext(context.WithValue(req.Context(), nonceKey{}, nonce))
+
+		hdr := w.Header()
+		csp := "Content-Security-Policy"
+		if s.ReportOnly {
+			csp = "Content-Security-Policy-Report-Only"
+		}
+		hdr.Set(csp, s.Policy(nonce))
+		if len(s.FrameAncestors) == 0 {
+			hdr.Set("X-Frame-Options", "DENY")
+		}
+		hdr.Set("X-Content-Type-Options", "nosniff")
+		policy := s.ReferrerPolicy
+		if policy == "" {
+			policy = DefaultReferrerPolicy
+		}
+		hdr.Set("Referrer-Policy", policy)
+		if isTLS(req) && s.HSTSSeconds >= 0 {
+			age := s.HSTSSeconds
+			if age == 0 {
+				age = DefaultHSTSSeconds
+			}
+			hdr.Set("Strict-Transport-Security", "max-age="+strconv.Itoa(age))
+		}
+
+		h.ServeHTTP(w, req)
+	})
+}
+
+// Policy returns the Content-Security-Policy allowing inline scripts with nonce.
+func (s *Headers) Policy(nonce string) string {
+	frames := "'none'"
+	if len(s.FrameAncestors) > 0 {
+		frames = strings.Join(s.FrameAncestors, " ")
+	}
+
+	directives := []string{
+		"default-src 'self'",
+		"script-src 'self' 'nonce-" + nonce + "'",
+		// inline styles are allowed as the wallboard sizes its bars with style attributes.
+		"style-src 'self' 'unsafe-inline'",
+		"font-src 'self'",
+		"img-src 'self' data:",
+		"connect-src 'self'",
+		"form-action 'self'",
+		"base-uri 'none'",
+		"object-src 'none'",
+		"frame-ancestors " + frames,
+	}
+	return strings.Join(directives, "; ")
+}
+
+// isTLS returns true when the request reached this server or the proxy in front of it
+// over HTTPS. Browsers ignore HSTS sent over HTTP so a forged header has no effect.
+func isTLS(req *http.Request) bool {
+	return req.TLS != nil || strings.EqualFold(req.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+// Nonce returns the nonce of req, it is empty when req did not pass through Headers.
+func Nonce(req *http.Request) string {
+	nonce, _ := req.Context().Value(nonceKey{}).(string)
+	return nonce
+}
+
+// Funcs returns the template functions for req, cspNonce returns the nonce inline scripts
+// must carry. A nil req is used when parsing the templates.
+func Funcs(req *http.Request) template.FuncMap {
+	return template.FuncMap{
+		"cspNonce": func() string {
+			if req == nil {
+				return ""
+			}
+			return Nonce(req)
+		},
+	}
+}
+
+func newNonce() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,135 @@
+package secure_test
+
+import (
+	"bytes"
+	"crypto/tls"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie/secure"
+)
+
+func serve(s *secure.Headers, req *http.Request) (*httptest.ResponseRecorder, string) {
+	var nonce string
+	h := s.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		nonce = secure.Nonce(req)
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w, nonce
+}
+
+func Test_Handler(t *testing.T) {
+	t.Parallel()
+	w, nonce := serve(&secure.Headers{}, httptest.NewRequest(http.MethodGet, "/tshirt", nil))
+
+	if nonce == "" {
+		t.Fatal("got empty nonce, want a nonce in the request context")
+	}
+	csp := w.Header().Get("Content-Security-Policy")
+	for _, want := range []string{"script-src 'self' 'nonce-" + nonce + "'", "frame-ancestors 'none'", "object-src 'none'"} {
+		if !strings.Contains(csp, want) {
+			t.Errorf("got policy %q, want it to contain %q", csp, want)
+		}
+	}
+	if strings.Contains(csp, "script-src 'self' 'unsafe-inline'") {
+		t.Errorf("got policy %q, want inline scripts limited to the nonce", csp)
+	}
+	if strings.Contains(csp, "https:") {
+		t.Errorf("got policy %q, want only the site's own origin", csp)
+	}
+
+	td := map[string]string{
+		"X-Frame-Options":           "DENY",
+		"Referrer-Policy":           secure.DefaultReferrerPolicy,
+		"X-Content-Type-Options":    "nosniff",
+		"Strict-Transport-Security": "",
+	}
+	for k, want := range td {
+		if got := w.Header().Get(k); got != want {
+			t.Errorf("got %v %q, want %q", k, got, want)
+		}
+	}
+
+	_, other := serve(&secure.Headers{}, httptest.NewRequest(http.MethodGet, "/tshirt", nil))
+	if other == nonce {
+		t.Errorf("got nonce %q for two requests, want a new nonce per request", nonce)
+	}
+}
+
+func Test_Handler_kiosk(t *testing.T) {
+	t.Parallel()
+	s := &secure.Headers{
+		FrameAncestors: []string{"https://dashboard.example.com"},
+		ReferrerPolicy: "no-referrer",
+		ReportOnly:     true,
+	}
+	w, _ := serve(s, httptest.NewRequest(http.MethodGet, "/wallboard", nil))
+
+	if w.Header().Get("X-Frame-Options") != "" {
+		t.Errorf("got X-Frame-Options %q, want none so the frame ancestors apply", w.Header().Get("X-Frame-Options"))
+	}
+	if w.Header().Get("Content-Security-Policy") != "" {
+		t.Error("got an enforced policy, want report only")
+	}
+	csp := w.Header().Get("Content-Security-Policy-Report-Only")
+	if !strings.Contains(csp, "frame-ancestors https://dashboard.example.com") {
+		t.Errorf("got policy %q, want the dashboard as a frame ancestor", csp)
+	}
+	if w.Header().Get("Referrer-Policy") != "no-referrer" {
+		t.Errorf("got Referrer-Policy %q, want no-referrer", w.Header().Get("Referrer-Policy"))
+	}
+}
+
+func Test_Handler_HSTS(t *testing.T) {
+	t.Parallel()
+	tlsReq := httptest.NewRequest(http.MethodGet, "/", nil)
+	tlsReq.TLS = &tls.ConnectionState{}
+	proxied := httptest.NewRequest(http.MethodGet, "/", nil)
+	proxied.Header.Set("X-Forwarded-Proto", "https")
+
+	td := []struct {
+		name    string
+		seconds int
+		req     *http.Request
+		want    string
+	}{
+		{"tls", 0, tlsReq, "max-age=31536000"},
+		{"proxied tls", 600, proxied, "max-age=600"},
+		{"disabled", -1, tlsReq, ""},
+		{"plain", 0, httptest.NewRequest(http.MethodGet, "/", nil), ""},
+	}
+
+	for _, tc := range td {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			w, _ := serve(&secure.Headers{HSTSSeconds: tc.seconds}, tc.req)
+			got := w.Header().Get("Strict-Transport-Security")
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func Test_Funcs(t *testing.T) {
+	t.Parallel()
+	tmpl := template.Must(template.New("").Funcs(secure.Funcs(nil)).Parse(`<script nonce="{{ cspNonce }}"></script>`))
+
+	var nonce string
+	var buf bytes.Buffer
+	h := (&secure.Headers{}).Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		nonce = secure.Nonce(req)
+		template.Must(tmpl.Clone()).Funcs(secure.Funcs(req)).Execute(&buf, nil)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := `<script nonce="` + nonce + `"></script>`
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,73 @@
+package share_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nfisher/wallie/share"
+)
+
+func Test_Verify(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2019, 1, 9, 12, 0, 0, 0, time.UTC)
+	signed, err := share.URL("s3cret", "/kanban", "ABC", now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	td := []struct {
+		name   string
+		secret string
+		url    string
+		now    time.Time
+		err    error
+	}{
+		{"valid", "s3cret", signed, now, nil},
+		{"extra parameters", "s3cret", signed + "&slide=3", now, nil},
+		{"expired", "s3cret", signed, now.Add(2 * time.Hour), share.ErrExpired},
+		{"wrong secret", "other", signed, now, share.ErrInvalid},
+		{"no secret", "", signed, now, share.ErrNoSecret},
+		{"other path", "s3cret", "/tshirt?" + mustParse(signed).RawQuery, now, share.ErrInvalid},
+		{"other project", "s3cret", "/kanban?project=XYZ&share=" + mustParse(signed).Query().Get("share"), now, share.ErrInvalid},
+		{"extended expiry", "s3cret", "/kanban?project=ABC&share=9999999999." + sig(signed), now, share.ErrInvalid},
+		{"missing token", "s3cret", "/kanban?project=ABC", now, share.ErrInvalid},
+	}
+
+	for _, tc := range td {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := share.Verify(tc.secret, mustParse(tc.url), tc.now)
+			if err != tc.err {
+				t.Errorf("got err %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func Test_Token_requires_secret(t *testing.T) {
+	t.Parallel()
+	_, err := share.Token("", "/kanban", "ABC", time.Now())
+	if err != share.ErrNoSecret {
+		t.Errorf("got err %v, want %v", err, share.ErrNoSecret)
+	}
+}
+
+func mustParse(s string) *url.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func sig(s string) string {
+	token := mustParse(s).Query().Get("share")
+	for i := range token {
+		if token[i] == '.' {
+			return token[i+1:]
+		}
+	}
+	return ""
+}
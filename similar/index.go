@@ -0,0 +1,261 @@
+package similar
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/nfisher/wallie/project"
+)
+
+// New creates an index which is persisted to the file at path.
+func New(path string) *Index {
+	return &Index{path: path}
+}
+
+// Index is a TF-IDF index of completed stories by project. Once built it is
+// queried without any calls to Jira.
+type Index struct {
+	path     string
+	corpora  map[string]*corpus
+	isLoaded bool
+	sync.RWMutex
+}
+
+// Index replaces the indexed stories of projectID with the sized stories provided.
+func (idx *Index) Index(projectID string, stories []project.Completed) error {
+	var ss []project.Story
+	for _, v := range stories {
+		if v.Size == project.Unsized || v.Size == "" {
+			continue
+		}
+		ss = append(ss, v.Story)
+	}
+
+	idx.Lock()
+	defer idx.Unlock()
+
+	err := idx.load()
+	if err != nil {
+		return err
+	}
+
+	idx.corpora[projectID] = newCorpus(ss)
+
+	return idx.save()
+}
+
+// Similar returns up to n of the indexed stories most similar to story, most similar first.
+func (idx *Index) Similar(projectID string, story project.Story, n int) ([]project.Neighbour, error) {
+	idx.RLock()
+	isLoaded := idx.isLoaded
+	idx.RUnlock()
+
+	if !isLoaded {
+		idx.Lock()
+		err := idx.load()
+		idx.Unlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	idx.RLock()
+	defer idx.RUnlock()
+
+	c, ok := idx.corpora[projectID]
+	if !ok {
+		return nil, nil
+	}
+
+	return c.similar(story, n), nil
+}
+
+func (idx *Index) load() error {
+	if idx.isLoaded {
+		return nil
+	}
+	idx.corpora = make(map[string]*corpus)
+
+	r, err := os.Open(idx.path)
+	if os.IsNotExist(err) {
+		idx.isLoaded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var stored map[string][]project.Story
+	err = json.NewDecoder(r).Decode(&stored)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range stored {
+		idx.corpora[k] = newCorpus(v)
+	}
+	idx.isLoaded = true
+
+	return nil
+}
+
+func (idx *Index) save() error {
+	stored := make(map[string][]project.Story)
+	for k, v := range idx.corpora {
+		stored[k] = v.stories
+	}
+
+	tmp := idx.path + ".tmp"
+	w, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	err = json.NewEncoder(w).Encode(stored)
+	if err != nil {
+		w.Close()
+		return err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, idx.path)
+}
+
+type vector map[string]float64
+
+type corpus struct {
+	stories []project.Story
+	vectors []vector
+	idf     map[string]float64
+}
+
+func newCorpus(stories []project.Story) *corpus {
+	c := &corpus{
+		stories: stories,
+		idf:     make(map[string]float64),
+	}
+
+	var counts []map[string]int
+	df := make(map[string]int)
+	for _, s := range stories {
+		tc := termCounts(s)
+		for t := range tc {
+			df[t]++
+		}
+		counts = append(counts, tc)
+	}
+
+	n := float64(len(stories))
+	for t, v := range df {
+		c.idf[t] = math.Log(n/float64(v)) + 1.0
+	}
+
+	for _, tc := range counts {
+		c.vectors = append(c.vectors, c.vector(tc))
+	}
+
+	return c
+}
+
+func (c *corpus) vector(tc map[string]int) vector {
+	var total int
+	for _, v := range tc {
+		total += v
+	}
+
+	vec := make(vector)
+	var norm float64
+	for t, v := range tc {
+		idf, ok := c.idf[t]
+		if !ok {
+			continue
+		}
+		w := float64(v) / float64(total) * idf
+		vec[t] = w
+		norm += w * w
+	}
+
+	norm = math.Sqrt(norm)
+	if norm == 0 {
+		return vec
+	}
+	for t := range vec {
+		vec[t] /= norm
+	}
+
+	return vec
+}
+
+func (c *corpus) similar(story project.Story, n int) []project.Neighbour {
+	q := c.vector(termCounts(story))
+
+	var nn []project.Neighbour
+	for i, v := range c.vectors {
+		if c.stories[i].ID == story.ID {
+			continue
+		}
+		score := dot(q, v)
+		if score <= 0 {
+			continue
+		}
+		nn = append(nn, project.Neighbour{Story: c.stories[i], Score: score})
+	}
+
+	sort.SliceStable(nn, func(i, j int) bool {
+		return nn[i].Score > nn[j].Score
+	})
+
+	if len(nn) > n {
+		nn = nn[:n]
+	}
+
+	return nn
+}
+
+func dot(a, b vector) float64 {
+	var sum float64
+	for t, v := range a {
+		sum += v * b[t]
+	}
+	return sum
+}
+
+func termCounts(s project.Story) map[string]int {
+	tc := make(map[string]int)
+	for _, t := range tokenise(s.Title + " " + s.Description) {
+		tc[t]++
+	}
+	return tc
+}
+
+func tokenise(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var tokens []string
+	for _, f := range fields {
+		if len(f) < 2 || stopWords[f] {
+			continue
+		}
+		tokens = append(tokens, f)
+	}
+	return tokens
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "has": true, "have": true, "in": true, "is": true,
+	"it": true, "its": true, "of": true, "on": true, "or": true, "so": true, "that": true,
+	"the": true, "this": true, "to": true, "was": true, "we": true, "will": true, "with": true,
+}
@@ -0,0 +1,55 @@
+package similar_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/similar"
+)
+
+func Test_Similar(t *testing.T) {
+	dir, err := ioutil.TempDir("", "similar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "index.json")
+	idx := similar.New(path)
+
+	err = idx.Index("ABC", []project.Completed{
+		{Story: project.Story{ID: "ABC-1", Title: "Add login page", Description: "Login form for Jira credentials", Size: project.Small}},
+		{Story: project.Story{ID: "ABC-2", Title: "Create service skeleton", Description: "Go service with Dockerfile", Size: project.Large}},
+		{Story: project.Story{ID: "ABC-3", Title: "Export backlog to CSV", Description: "CSV export of stories", Size: project.Medium}},
+		{Story: project.Story{ID: "ABC-4", Title: "Unsized login work"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reload from disk to ensure the index is persisted.
+	idx = similar.New(path)
+	nn, err := idx.Similar("ABC", project.Story{ID: "ABC-9", Title: "Logout from the login page"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nn) != 1 {
+		t.Fatalf("got len(Similar()) = %v, want 1", len(nn))
+	}
+
+	if nn[0].ID != "ABC-1" {
+		t.Errorf("got nearest = %v, want ABC-1", nn[0].ID)
+	}
+
+	nn, err = idx.Similar("DEF", project.Story{Title: "login"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nn) != 0 {
+		t.Errorf("got len(Similar()) = %v, want 0 for unindexed project", len(nn))
+	}
+}
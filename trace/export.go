@@ -0,0 +1,206 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Stdout returns an exporter which writes each span to w as a line of JSON.
+func Stdout(w io.Writer) Exporter {
+	return &lineExporter{w: w}
+}
+
+type lineExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+type line struct {
+	Name       string                 `json:"name"`
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Start      time.Time              `json:"start"`
+	DurationMS float64                `json:"duration_ms"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+func (e *lineExporter) Export(spans []*Span) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, s := range spans {
+		l := line{
+			Name:       s.Name,
+			TraceID:    s.TraceID.String(),
+			SpanID:     s.SpanID.String(),
+			Start:      s.Start.UTC(),
+			DurationMS: float64(s.End.Sub(s.Start)) / float64(time.Millisecond),
+			Error:      s.Err,
+		}
+		if s.Parent != (SpanID{}) {
+			l.ParentID = s.Parent.String()
+		}
+		if len(s.Attributes) > 0 {
+			l.Attributes = make(map[string]interface{})
+			for _, a := range s.Attributes {
+				l.Attributes[a.Key] = a.Value
+			}
+		}
+		err := enc.Encode(&l)
+		if err != nil {
+			return err
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+// OTLP returns an exporter which posts spans in the OTLP/HTTP JSON encoding to endpoint,
+// for example http://localhost:4318/v1/traces.
+func OTLP(endpoint, service string, client *http.Client) Exporter {
+	return &otlpExporter{endpoint: endpoint, service: service, client: client}
+}
+
+type otlpExporter struct {
+	endpoint string
+	service  string
+	client   *http.Client
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              Kind            `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+// otlpValue holds one of its fields, 64 bit integers are encoded as strings.
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+const otlpStatusError = 2
+
+func otlpAttr(key string, v interface{}) otlpAttribute {
+	var value otlpValue
+	switch t := v.(type) {
+	case string:
+		value.StringValue = &t
+	case int:
+		s := strconv.Itoa(t)
+		value.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(t, 10)
+		value.IntValue = &s
+	case float64:
+		value.DoubleValue = &t
+	case bool:
+		value.BoolValue = &t
+	default:
+		s := fmt.Sprint(t)
+		value.StringValue = &s
+	}
+	return otlpAttribute{Key: key, Value: value}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func (e *otlpExporter) Export(spans []*Span) error {
+	scope := otlpScopeSpans{Scope: otlpScope{Name: "github.com/nfisher/wallie/trace"}}
+	for _, s := range spans {
+		out := otlpSpan{
+			TraceID:           s.TraceID.String(),
+			SpanID:            s.SpanID.String(),
+			Name:              s.Name,
+			Kind:              s.Kind,
+			StartTimeUnixNano: unixNano(s.Start),
+			EndTimeUnixNano:   unixNano(s.End),
+		}
+		if s.Parent != (SpanID{}) {
+			out.ParentSpanID = s.Parent.String()
+		}
+		for _, a := range s.Attributes {
+			out.Attributes = append(out.Attributes, otlpAttr(a.Key, a.Value))
+		}
+		if s.Err != "" {
+			out.Status = otlpStatus{Code: otlpStatusError, Message: s.Err}
+		}
+		scope.Spans = append(scope.Spans, out)
+	}
+
+	body := otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: []otlpAttribute{otlpAttr("service.name", e.service)}},
+		ScopeSpans: []otlpScopeSpans{scope},
+	}}}
+	b, err := json.Marshal(&body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("otlp export failed with status code %v: %s", resp.StatusCode, msg)
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+
+	return nil
+}
@@ -0,0 +1,284 @@
+// Package trace records spans of work and exports them to stdout or an OpenTelemetry
+// collector. Spans are only recorded once a Tracer has been installed with Install.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Kind describes the relationship of a span to its parent.
+type Kind int
+
+// Kinds of span, the values match OpenTelemetry.
+const (
+	Internal Kind = 1
+	Server   Kind = 2
+	Client   Kind = 3
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// Attribute is a key value annotation of a span. Values should be strings, ints, floats
+// or bools.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span is a timed operation in a trace. A nil Span is valid and records nothing.
+type Span struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Parent     SpanID
+	Name       string
+	Kind       Kind
+	Start      time.Time
+	End        time.Time
+	Attributes []Attribute
+	// Err describes why the operation failed, it's empty on success.
+	Err string
+
+	tracer *Tracer
+	mu     sync.Mutex
+	ended  bool
+}
+
+// Set adds an attribute to the span.
+func (s *Span) Set(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Attributes = append(s.Attributes, Attribute{key, value})
+}
+
+// Fail marks the span as failed with err, a nil err is ignored.
+func (s *Span) Fail(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Err = err.Error()
+}
+
+// Finish ends the span and queues it for export, later calls have no effect.
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.End = time.Now()
+	s.mu.Unlock()
+	s.tracer.queue(s)
+}
+
+// Exporter sends finished spans to their destination.
+type Exporter interface {
+	Export(spans []*Span) error
+}
+
+// Tracer creates spans and exports them in batches.
+type Tracer struct {
+	exporter Exporter
+	spans    chan *Span
+	flush    chan chan struct{}
+	dropped  uint64
+	// OnError is called when an export fails, it defaults to ignoring the error.
+	OnError func(error)
+}
+
+const batchSize = 256
+
+// NewTracer returns a Tracer which exports spans every interval or when a batch is full.
+func NewTracer(e Exporter, interval time.Duration) *Tracer {
+	t := &Tracer{
+		exporter: e,
+		spans:    make(chan *Span, 4*batchSize),
+		flush:    make(chan chan struct{}),
+		OnError:  func(error) {},
+	}
+	go t.run(interval)
+	return t
+}
+
+func (t *Tracer) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var batch []*Span
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+		err := t.exporter.Export(batch)
+		if err != nil {
+			t.OnError(err)
+		}
+		batch = nil
+	}
+
+	for {
+		select {
+		case s := <-t.spans:
+			batch = append(batch, s)
+			if len(batch) >= batchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case done := <-t.flush:
+			for n := len(t.spans); n > 0; n-- {
+				batch = append(batch, <-t.spans)
+			}
+			export()
+			close(done)
+		}
+	}
+}
+
+func (t *Tracer) queue(s *Span) {
+	select {
+	case t.spans <- s:
+	default:
+		atomic.AddUint64(&t.dropped, 1)
+	}
+}
+
+// Dropped is the number of spans discarded because the export queue was full.
+func (t *Tracer) Dropped() uint64 {
+	return atomic.LoadUint64(&t.dropped)
+}
+
+// Flush exports the finished spans which are queued.
+func (t *Tracer) Flush() {
+	done := make(chan struct{})
+	t.flush <- done
+	<-done
+}
+
+// Start begins a span which is a child of the span in ctx, the returned context carries
+// the new span.
+func (t *Tracer) Start(ctx context.Context, name string, kind Kind) (context.Context, *Span) {
+	s := &Span{
+		SpanID: newSpanID(),
+		Name:   name,
+		Kind:   kind,
+		Start:  time.Now(),
+		tracer: t,
+	}
+	if parent, ok := parentOf(ctx); ok {
+		s.TraceID = parent.trace
+		s.Parent = parent.span
+	} else {
+		_, _ = rand.Read(s.TraceID[:])
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+var global atomic.Value
+
+// Install makes t the tracer used by Start.
+func Install(t *Tracer) {
+	global.Store(t)
+}
+
+// Installed returns the tracer used by Start or nil when tracing is disabled.
+func Installed() *Tracer {
+	t, _ := global.Load().(*Tracer)
+	return t
+}
+
+// Start begins a span with the installed tracer. The span is nil when no tracer has
+// been installed.
+func Start(ctx context.Context, name string, kind Kind) (context.Context, *Span) {
+	t := Installed()
+	if t == nil {
+		return ctx, nil
+	}
+	return t.Start(ctx, name, kind)
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+type spanContext struct {
+	trace TraceID
+	span  SpanID
+}
+
+// FromContext returns the span carried by ctx or nil when there is none.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+func parentOf(ctx context.Context) (spanContext, bool) {
+	if s := FromContext(ctx); s != nil {
+		return spanContext{s.TraceID, s.SpanID}, true
+	}
+	sc, ok := ctx.Value(remoteKey{}).(spanContext)
+	return sc, ok
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+// Header is the W3C trace context header spans are propagated in.
+const Header = "traceparent"
+
+// Inject adds the trace context of the span in ctx to h.
+func Inject(ctx context.Context, h http.Header) {
+	sc, ok := parentOf(ctx)
+	if !ok {
+		return
+	}
+	h.Set(Header, "00-"+sc.trace.String()+"-"+sc.span.String()+"-01")
+}
+
+// Extract returns a context whose spans continue the trace in the traceparent of h.
+func Extract(ctx context.Context, h http.Header) context.Context {
+	parts := strings.Split(h.Get(Header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return ctx
+	}
+	var sc spanContext
+	if !decode(sc.trace[:], parts[1]) || !decode(sc.span[:], parts[2]) {
+		return ctx
+	}
+	if sc.trace == (TraceID{}) || sc.span == (SpanID{}) {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+func decode(dst []byte, s string) bool {
+	if len(s) != 2*len(dst) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
@@ -0,0 +1,166 @@
+package trace_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nfisher/wallie/trace"
+)
+
+type recorder struct {
+	mu    sync.Mutex
+	spans []*trace.Span
+}
+
+func (r *recorder) Export(spans []*trace.Span) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func Test_Start_child(t *testing.T) {
+	t.Parallel()
+	rec := &recorder{}
+	tracer := trace.NewTracer(rec, time.Hour)
+
+	ctx, parent := tracer.Start(context.Background(), "GET /tshirt", trace.Server)
+	_, child := tracer.Start(ctx, "jira.search", trace.Client)
+	child.Set("page", 2)
+	child.Fail(errors.New("unexpected status code 500"))
+	child.Finish()
+	child.Finish()
+	parent.Finish()
+	tracer.Flush()
+
+	if len(rec.spans) != 2 {
+		t.Fatalf("got %v spans, want 2", len(rec.spans))
+	}
+	got := rec.spans[0]
+	if got.TraceID != parent.TraceID {
+		t.Errorf("got trace %v, want %v", got.TraceID, parent.TraceID)
+	}
+	if got.Parent != parent.SpanID {
+		t.Errorf("got parent %v, want %v", got.Parent, parent.SpanID)
+	}
+	if got.Err != "unexpected status code 500" {
+		t.Errorf("got error %q, want unexpected status code 500", got.Err)
+	}
+	if len(got.Attributes) != 1 || got.Attributes[0].Value != 2 {
+		t.Errorf("got attributes %v, want page=2", got.Attributes)
+	}
+}
+
+func Test_Start_disabled(t *testing.T) {
+	t.Parallel()
+	ctx, span := trace.Start(context.Background(), "noop", trace.Internal)
+	span.Set("ignored", true)
+	span.Finish()
+	if span != nil || trace.FromContext(ctx) != nil {
+		t.Error("got a span, want nil without an installed tracer")
+	}
+}
+
+func Test_Propagation(t *testing.T) {
+	t.Parallel()
+	const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	td := []struct {
+		name   string
+		header string
+		ok     bool
+	}{
+		{"valid", parent, true},
+		{"upper case", strings.ToUpper(parent), false},
+		{"zero trace", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"short span", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa-01", false},
+		{"invalid version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"missing", "", false},
+	}
+
+	tracer := trace.NewTracer(&recorder{}, time.Hour)
+	for _, tc := range td {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			in := http.Header{}
+			in.Set(trace.Header, tc.header)
+			ctx, span := tracer.Start(trace.Extract(context.Background(), in), "GET /", trace.Server)
+
+			continued := span.TraceID.String() == "4bf92f3577b34da6a3ce929d0e0e4736" &&
+				span.Parent.String() == "00f067aa0ba902b7"
+			if continued != tc.ok {
+				t.Errorf("got continued %v, want %v", continued, tc.ok)
+			}
+
+			out := http.Header{}
+			trace.Inject(ctx, out)
+			want := "00-" + span.TraceID.String() + "-" + span.SpanID.String() + "-01"
+			if out.Get(trace.Header) != want {
+				t.Errorf("got %q, want %q", out.Get(trace.Header), want)
+			}
+		})
+	}
+}
+
+func Test_OTLP(t *testing.T) {
+	t.Parallel()
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/traces" || req.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+	}))
+	defer srv.Close()
+
+	span := &trace.Span{
+		Name:       "template story_estimation_content",
+		Kind:       trace.Internal,
+		Start:      time.Unix(1, 0),
+		End:        time.Unix(2, 0),
+		Attributes: []trace.Attribute{{"template", "story_estimation_content"}, {"bytes", 42}},
+		Err:        "boom",
+	}
+	span.TraceID[0] = 1
+	span.SpanID[0] = 2
+
+	err := trace.OTLP(srv.URL+"/v1/traces", "wallie", srv.Client()).Export([]*trace.Span{span})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := json.Marshal(body)
+	for _, want := range []string{
+		`"service.name"`,
+		`"traceId":"01000000000000000000000000000000"`,
+		`"spanId":"0200000000000000"`,
+		`"startTimeUnixNano":"1000000000"`,
+		`{"key":"bytes","value":{"intValue":"42"}}`,
+		`"status":{"code":2,"message":"boom"}`,
+	} {
+		if !bytes.Contains(b, []byte(want)) {
+			t.Errorf("got %s, want it to contain %s", b, want)
+		}
+	}
+}
+
+func Test_Stdout(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	span := &trace.Span{Name: "jira.UpdateIssue", Start: time.Unix(0, 0), End: time.Unix(0, int64(3*time.Millisecond))}
+	err := trace.Stdout(&buf).Export([]*trace.Span{span})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"name":"jira.UpdateIssue"`) || !strings.Contains(buf.String(), `"duration_ms":3`) {
+		t.Errorf("got %s, want the span name and duration", buf.String())
+	}
+}
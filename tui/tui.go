@@ -0,0 +1,152 @@
+// Package tui walks the unsized stories of a backlog in a terminal and sizes each with a single keypress.
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nfisher/wallie/project"
+)
+
+// maxDescriptionLines limits how much of a description is shown so the keys remain on screen.
+const maxDescriptionLines = 20
+
+// Session is an interactive estimation session for a single project.
+type Session struct {
+	Client  project.Client
+	History project.History
+	Scale   project.Scale
+	Project string
+
+	in      *bufio.Reader
+	out     io.Writer
+	last    *change
+	message string
+}
+
+type change struct {
+	index    int
+	previous project.Story
+}
+
+// Run presents each unsized story and reads a key from in until all stories are visited or
+// the user quits, the last estimate can still be undone after the last story. The keys 1-9
+// size the current story, s skips it, u undoes the last estimate and q quits.
+func (s *Session) Run(in io.Reader, out io.Writer) error {
+	s.in = bufio.NewReader(in)
+	s.out = out
+
+	backlog, err := s.Client.ListStories(s.Project)
+	if err != nil {
+		return err
+	}
+	stories := backlog.BySize()[0].Stories
+	sizes := s.Scale.Sizes()
+
+	for i := 0; i < len(stories) || s.last != nil; {
+		done := i == len(stories)
+		if done {
+			s.showDone()
+		} else {
+			s.show(stories, i, sizes)
+		}
+
+		b, err := s.in.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case b == 'q':
+			return nil
+
+		case b == 'u':
+			if s.last == nil {
+				s.status("nothing to undo")
+				continue
+			}
+			p := s.last.previous
+			_, err = project.Resize(s.Client, s.History, s.Scale, s.Project, p.ID, project.StoryPatch{Size: &p.Size}, "tui", "")
+			if err != nil {
+				s.status(err.Error())
+				continue
+			}
+			i = s.last.index
+			stories[i] = p
+			s.last = nil
+
+		case done:
+			// only undo and quit remain after the last story.
+
+		case b == 's' || b == ' ':
+			i++
+
+		case b >= '1' && b <= '9' && int(b-'1') < len(sizes):
+			size := sizes[b-'1']
+			story, err := project.Resize(s.Client, s.History, s.Scale, s.Project, stories[i].ID, project.StoryPatch{Size: &size}, "tui", "")
+			if err != nil {
+				s.status(err.Error())
+				continue
+			}
+			s.last = &change{index: i, previous: stories[i]}
+			stories[i] = story
+			i++
+		}
+	}
+
+	fmt.Fprintln(s.out, "\nAll stories visited.")
+	return nil
+}
+
+// showDone prompts to undo the last change or quit once every story has been visited.
+func (s *Session) showDone() {
+	fmt.Fprint(s.out, "\033[H\033[2J")
+	fmt.Fprintln(s.out, "All stories visited.")
+	fmt.Fprintln(s.out)
+	fmt.Fprintln(s.out, "[u] undo  [q] quit")
+
+	if s.message != "" {
+		fmt.Fprintf(s.out, "\n%s\n", s.message)
+		s.message = ""
+	}
+}
+
+func (s *Session) show(stories []project.Story, i int, sizes []project.Size) {
+	story := stories[i]
+
+	fmt.Fprint(s.out, "\033[H\033[2J")
+	fmt.Fprintf(s.out, "%s  (%d of %d)  %s\n\n", story.ID, i+1, len(stories), story.Author)
+	fmt.Fprintf(s.out, "\033[1m%s\033[0m\n\n", story.Title)
+
+	lines := strings.Split(story.Description, "\n")
+	if len(lines) > maxDescriptionLines {
+		lines = append(lines[:maxDescriptionLines], "...")
+	}
+	fmt.Fprintln(s.out, strings.Join(lines, "\n"))
+	fmt.Fprintln(s.out)
+
+	var keys []string
+	for j, sz := range sizes {
+		if j >= 9 {
+			break
+		}
+		keys = append(keys, fmt.Sprintf("[%d] %s", j+1, sz))
+	}
+	keys = append(keys, "[s] skip", "[u] undo", "[q] quit")
+	fmt.Fprintln(s.out, strings.Join(keys, "  "))
+
+	if s.message != "" {
+		fmt.Fprintf(s.out, "\n%s\n", s.message)
+		s.message = ""
+	}
+}
+
+// status sets a message to display below the keys the next time a story is shown.
+func (s *Session) status(msg string) {
+	s.message = msg
+}
@@ -0,0 +1,123 @@
+package tui_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nfisher/wallie/project"
+	"github.com/nfisher/wallie/project/projecttest"
+	"github.com/nfisher/wallie/tui"
+)
+
+func Test_Run(t *testing.T) {
+	client := &projecttest.Client{
+		Stories: []project.Story{
+			{ID: "ABC-1", Title: "Add login page", Size: project.Unsized},
+			{ID: "ABC-2", Title: "Create service skeleton", Size: project.Unsized},
+			{ID: "ABC-3", Title: "Already sized", Size: project.Large},
+			{ID: "ABC-4", Title: "Export backlog", Size: project.Unsized},
+		},
+	}
+	history := &projecttest.History{}
+	s := &tui.Session{
+		Client:  client,
+		History: history,
+		Scale:   project.TShirt,
+		Project: "ABC",
+	}
+
+	// size ABC-1 as S, ABC-2 as XXL, undo ABC-2, resize it as M, skip ABC-4.
+	var out bytes.Buffer
+	err := s.Run(strings.NewReader("26u3s"), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	td := []struct {
+		id   string
+		size project.Size
+	}{
+		{"ABC-1", project.Small},
+		{"ABC-2", project.Medium},
+		{"ABC-3", project.Large},
+		{"ABC-4", project.Unsized},
+	}
+	for i, tc := range td {
+		if client.Stories[i].Size != tc.size {
+			t.Errorf("got %v size = %v, want %v", tc.id, client.Stories[i].Size, tc.size)
+		}
+	}
+
+	if len(history.Changes) != 4 {
+		t.Errorf("got len(history) = %v, want 4", len(history.Changes))
+	}
+
+	if !strings.Contains(out.String(), "All stories visited.") {
+		t.Error("got session without completion message")
+	}
+}
+
+func Test_Run_undo_last(t *testing.T) {
+	client := &projecttest.Client{
+		Stories: []project.Story{
+			{ID: "ABC-1", Title: "Add login page", Size: project.Unsized},
+		},
+	}
+	history := &projecttest.History{}
+	s := &tui.Session{
+		Client:  client,
+		History: history,
+		Scale:   project.TShirt,
+		Project: "ABC",
+	}
+
+	// size the only story as XS then undo it from the completion prompt.
+	var out bytes.Buffer
+	err := s.Run(strings.NewReader("1u"), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.Stories[0].Size != project.Unsized {
+		t.Errorf("got ABC-1 size = %v, want %v", client.Stories[0].Size, project.Unsized)
+	}
+
+	if len(history.Changes) != 2 {
+		t.Errorf("got len(history) = %v, want 2", len(history.Changes))
+	}
+
+	if !strings.Contains(out.String(), "[u] undo  [q] quit") {
+		t.Error("got session without undo prompt after the last story")
+	}
+}
+
+func Test_Run_history_failure(t *testing.T) {
+	client := &projecttest.Client{
+		Stories: []project.Story{
+			{ID: "ABC-1", Title: "Add login page", Size: project.Unsized},
+			{ID: "ABC-2", Title: "Create service skeleton", Size: project.Unsized},
+		},
+	}
+	s := &tui.Session{
+		Client:  client,
+		History: &projecttest.History{Err: errors.New("disk full")},
+		Scale:   project.TShirt,
+		Project: "ABC",
+	}
+
+	// the estimate reaches Jira so the session moves on and it can be undone.
+	var out bytes.Buffer
+	err := s.Run(strings.NewReader("2u"), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.Stories[0].Size != project.Unsized {
+		t.Errorf("got ABC-1 size = %v, want %v", client.Stories[0].Size, project.Unsized)
+	}
+	if strings.Contains(out.String(), "disk full") {
+		t.Error("got history error, want the estimate to succeed")
+	}
+}